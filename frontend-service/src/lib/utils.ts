@@ -6,15 +6,64 @@ export function cn(...inputs: ClassValue[]) {
   return twMerge(clsx(inputs));
 }
 
+const API_URL = "http://localhost:8001";
+
+export const storeTokens = (data: { token: string; refreshToken?: string }) => {
+  localStorage.setItem("token", data.token);
+  if (data.refreshToken) {
+    localStorage.setItem("refreshToken", data.refreshToken);
+  }
+};
+
+// Refresh tokens are single use, so concurrent requests share one refresh
+let refreshing: Promise<boolean> | null = null;
+
+const refreshTokens = (): Promise<boolean> => {
+  if (!refreshing) {
+    refreshing = (async () => {
+      const refreshToken = localStorage.getItem("refreshToken");
+      if (!refreshToken) return false;
+
+      try {
+        const response = await fetch(`${API_URL}/api/auth/refresh`, {
+          method: "POST",
+          headers: { "Content-Type": "application/json" },
+          body: JSON.stringify({ refreshToken }),
+        });
+        if (!response.ok) {
+          localStorage.removeItem("token");
+          localStorage.removeItem("refreshToken");
+          return false;
+        }
+        storeTokens(await response.json());
+        return true;
+      } catch {
+        return false;
+      }
+    })().finally(() => {
+      refreshing = null;
+    });
+  }
+  return refreshing;
+};
+
 export const authFetch = async (url: string, options: RequestInit = {}) => {
-  const token = localStorage.getItem("token");
-  const headers = {
-    ...(options.headers || {}),
-    "Authorization": `Bearer ${token}`,
-    "Content-Type": "application/json",
-  };
-
-  return fetch(url, { ...options, headers });
+  const send = () =>
+    fetch(url, {
+      ...options,
+      headers: {
+        ...(options.headers || {}),
+        "Authorization": `Bearer ${localStorage.getItem("token")}`,
+        "Content-Type": "application/json",
+      },
+    });
+
+  // An expired access token is renewed once, then the request is retried
+  const response = await send();
+  if (response.status !== 401 || !(await refreshTokens())) {
+    return response;
+  }
+  return send();
 };
 
 export const aiResponseToString = (resp: AIResponse): string => {
@@ -6,7 +6,7 @@ import { Card } from "@/components/ui/card";
 import { Send, Loader2 } from "lucide-react";
 import { ScrollArea } from "@/components/ui/scroll-area";
 import aiAvatar from "@/assets/ai-avatar.jpg";
-import { aiResponseToString, authFetch } from "@/lib/utils";
+import { authFetch } from "@/lib/utils";
 import ReactMarkdown from "react-markdown";
 import remarkGfm from "remark-gfm";
 import { Message } from "@/interfaces/message";
@@ -23,6 +23,7 @@ const Dashboard = () => {
   ]);
   const [inputMessage, setInputMessage] = useState("");
   const [isLoading, setIsLoading] = useState(false);
+  const [sessionId, setSessionId] = useState<string | null>(null);
 
   const formatAISection = (text: string) => {
     // Replace '*' bullets with '-' for consistency
@@ -134,32 +135,15 @@ const Dashboard = () => {
     setIsLoading(true);
 
     try {
-      // Build full chat history for RAG (include both user & AI text)
-      const chatHistory = messages.map((msg) => ({
-        sender: msg.sender,
-        text:
-          typeof msg.text === "string"
-            ? msg.text
-            : aiResponseToString(msg.text),
-        timestamp: msg.timestamp,
-      }));
-
-      // Add the new user message
-      if (typeof userMessage.text === "string") {
-        chatHistory.push({
-          sender: "user",
-          text: userMessage.text,
-          timestamp: userMessage.timestamp,
-        });
-      }
-
       // Call backend API
       const response = await authFetch("http://localhost:8001/api/chat", {
         method: "POST",
         headers: { "Content-Type": "application/json" },
+        // The server keeps the conversation; the first answer opens the
+        // session that later messages continue
         body: JSON.stringify({
           message: userMessage.text,
-          history: chatHistory,
+          ...(sessionId && { sessionId }),
         }),
       });
 
@@ -168,6 +152,9 @@ const Dashboard = () => {
       }
 
       const data = await response.json();
+      if (data.session?.id) {
+        setSessionId(data.session.id);
+      }
 
       // Extract AI text
       let aiResponseText =
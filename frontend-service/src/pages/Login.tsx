@@ -5,6 +5,7 @@ import { Input } from "@/components/ui/input";
 import { Label } from "@/components/ui/label";
 import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
 import { toast } from "@/hooks/use-toast";
+import { storeTokens } from "@/lib/utils";
 import defaultImage from "@/assets/default.jpg";
 
 const AuthPage = () => {
@@ -14,18 +15,26 @@ const AuthPage = () => {
   const [password, setPassword] = useState("");
   const [fullName, setFullName] = useState("");
   const [isLoading, setIsLoading] = useState(false);
+  // Set while an account with two-factor authentication owes its code
+  const [challengeToken, setChallengeToken] = useState<string | null>(null);
+  const [code, setCode] = useState("");
 
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault();
     setIsLoading(true);
 
-    const endpoint = mode === "login" ? "login" : "register";
+    const endpoint = challengeToken ? "2fa/login" : mode === "login" ? "login" : "register";
+    const body = challengeToken
+      ? { challengeToken, code }
+      : mode === "login"
+        ? { email, password }
+        : { email, password, fullName };
 
     try {
       const response = await fetch(`http://localhost:8001/api/auth/${endpoint}`, {
         method: "POST",
         headers: { "Content-Type": "application/json" },
-        body: JSON.stringify(mode === "login" ? { email, password } : { email, password, fullName }),
+        body: JSON.stringify(body),
       });
 
       const data = await response.json();
@@ -35,12 +44,26 @@ const AuthPage = () => {
           title: mode === "login" ? "Login failed" : "Registration failed",
           description: data.error || "Something went wrong",
         });
+        // A revoked or expired challenge means starting over with the password
+        if (challengeToken && response.status === 401 && data.error !== "Invalid code") {
+          setChallengeToken(null);
+          setCode("");
+        }
         setIsLoading(false);
         return;
       }
 
-      // Store JWT token
-      localStorage.setItem("token", data.token);
+      if (data.twoFactorRequired) {
+        setChallengeToken(data.challengeToken);
+        setIsLoading(false);
+        return;
+      }
+
+      setChallengeToken(null);
+      setCode("");
+
+      // Store the JWT and the refresh token that renews it
+      storeTokens(data);
 
       toast({
         title: mode === "login" ? "Welcome back!" : "Registration successful",
@@ -85,60 +108,94 @@ const AuthPage = () => {
               {mode === "login" ? "Welcome back" : "Create an account"}
             </CardTitle>
             <CardDescription>
-              {mode === "login"
+              {challengeToken
+                ? "Enter the code from your authenticator app or one of your recovery codes"
+                : mode === "login"
                 ? "Enter your credentials to access your dashboard"
                 : "Fill in the form to register"}
             </CardDescription>
           </CardHeader>
           <CardContent>
             <form onSubmit={handleSubmit} className="space-y-4">
-              {mode === "register" && (
+              {challengeToken ? (
                 <div className="space-y-2">
-                  <Label htmlFor="fullName">Full Name</Label>
+                  <Label htmlFor="code">Authentication code</Label>
                   <Input
-                    id="fullName"
+                    id="code"
                     type="text"
-                    placeholder="John Doe"
-                    value={fullName}
-                    onChange={(e) => setFullName(e.target.value)}
+                    autoComplete="one-time-code"
+                    placeholder="123456 or a recovery code"
+                    value={code}
+                    onChange={(e) => setCode(e.target.value)}
                     required
+                    autoFocus
                     className="focus-fade"
                   />
                 </div>
+              ) : (
+                <>
+                  {mode === "register" && (
+                    <div className="space-y-2">
+                      <Label htmlFor="fullName">Full Name</Label>
+                      <Input
+                        id="fullName"
+                        type="text"
+                        placeholder="John Doe"
+                        value={fullName}
+                        onChange={(e) => setFullName(e.target.value)}
+                        required
+                        className="focus-fade"
+                      />
+                    </div>
+                  )}
+
+                  <div className="space-y-2">
+                    <Label htmlFor="email">Email</Label>
+                    <Input
+                      id="email"
+                      type="email"
+                      placeholder="your.email@example.com"
+                      value={email}
+                      onChange={(e) => setEmail(e.target.value)}
+                      required
+                      className="focus-fade"
+                    />
+                  </div>
+
+                  <div className="space-y-2">
+                    <Label htmlFor="password">Password</Label>
+                    <Input
+                      id="password"
+                      type="password"
+                      placeholder="••••••••"
+                      value={password}
+                      onChange={(e) => setPassword(e.target.value)}
+                      required
+                      className="focus-fade"
+                    />
+                  </div>
+                </>
               )}
 
-              <div className="space-y-2">
-                <Label htmlFor="email">Email</Label>
-                <Input
-                  id="email"
-                  type="email"
-                  placeholder="your.email@example.com"
-                  value={email}
-                  onChange={(e) => setEmail(e.target.value)}
-                  required
-                  className="focus-fade"
-                />
-              </div>
-
-              <div className="space-y-2">
-                <Label htmlFor="password">Password</Label>
-                <Input
-                  id="password"
-                  type="password"
-                  placeholder="••••••••"
-                  value={password}
-                  onChange={(e) => setPassword(e.target.value)}
-                  required
-                  className="focus-fade"
-                />
-              </div>
-
               <Button type="submit" className="w-full" size="lg" disabled={isLoading}>
-                {isLoading ? (mode === "login" ? "Signing in..." : "Registering...") : mode === "login" ? "Sign in" : "Register"}
+                {challengeToken
+                  ? isLoading ? "Verifying..." : "Verify"
+                  : isLoading ? (mode === "login" ? "Signing in..." : "Registering...") : mode === "login" ? "Sign in" : "Register"}
               </Button>
 
               <div className="text-center text-sm text-muted-foreground mt-2">
-                {mode === "login" ? (
+                {challengeToken ? (
+                  <button
+                    type="button"
+                    className="text-accent hover:underline"
+                    onClick={() => {
+                      setChallengeToken(null);
+                      setCode("");
+                    }}
+                  >
+                    Back to sign in
+                  </button>
+                ) : mode === "login" ? (
                   <>
                     Don't have an account?{" "}
                     <button
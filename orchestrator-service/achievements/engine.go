@@ -0,0 +1,150 @@
+// Package achievements awards badges for streaks and milestones in the
+// activities users log, judged from their daily rollups.
+package achievements
+
+import (
+	"math"
+	"time"
+
+	"orchestrator-service/catalog"
+	"orchestrator-service/models"
+)
+
+// Kinds of rule
+const (
+	// Streak counts the consecutive days, up to today, on which the user
+	// reached their daily goal for the type. A day whose goal is not met
+	// yet does not break the streak while it is still today.
+	Streak = "streak"
+	// Days counts the days whose total reached Threshold.
+	Days = "days"
+	// Total adds up every value ever logged.
+	Total = "total"
+)
+
+// Rule describes a badge and what earns it: reaching Target days or,
+// for Total, a Target total. Only values logged in Unit (or its aliases)
+// count.
+type Rule struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Kind        string  `json:"kind"`
+	Type        string  `json:"type"`
+	Unit        string  `json:"unit"`
+	Threshold   float64 `json:"threshold,omitempty"`
+	Target      float64 `json:"target"`
+}
+
+// Status is how far a user has got towards a badge.
+type Status struct {
+	Rule
+	Value   float64 `json:"value"`
+	Percent float64 `json:"percent"` // Of the target, capped at 100
+}
+
+// Reached reports whether the badge has been earned by now.
+func (s Status) Reached() bool {
+	return s.Value >= s.Target
+}
+
+// History is what rules are evaluated against: a user's daily totals and
+// their goals.
+type History struct {
+	totals map[string]map[string]float64 // "type/unit" -> date -> total
+	goals  []models.Goal
+	today  string
+}
+
+// NewHistory takes the daily totals from the user's rollups, which are
+// kept per day of loc in canonical units.
+func NewHistory(rollups []models.ActivityRollup, goals []models.Goal, loc *time.Location, now time.Time) *History {
+	h := &History{
+		totals: make(map[string]map[string]float64),
+		goals:  goals,
+		today:  now.In(loc).Format("2006-01-02"),
+	}
+
+	for _, rollup := range rollups {
+		if t, ok := catalog.Lookup(rollup.Type); !ok || !t.Summable {
+			continue
+		}
+
+		key := rollup.Type + "/" + rollup.Unit
+		if h.totals[key] == nil {
+			h.totals[key] = make(map[string]float64)
+		}
+		h.totals[key][rollup.Date] += rollup.Sum
+	}
+	return h
+}
+
+// Evaluate reports the user's progress on each rule.
+func Evaluate(rules []Rule, history *History) []Status {
+	statuses := make([]Status, len(rules))
+	for i, rule := range rules {
+		var value float64
+		switch rule.Kind {
+		case Streak:
+			value = float64(history.streak(rule))
+		case Days:
+			for _, total := range history.totals[rule.Type+"/"+rule.Unit] {
+				if total >= rule.Threshold {
+					value++
+				}
+			}
+		case Total:
+			for _, total := range history.totals[rule.Type+"/"+rule.Unit] {
+				value += total
+			}
+		}
+
+		statuses[i] = Status{
+			Rule:    rule,
+			Value:   math.Round(value*100) / 100,
+			Percent: math.Min(100, math.Round(value/rule.Target*1000)/10),
+		}
+	}
+	return statuses
+}
+
+func (h *History) streak(rule Rule) int {
+	totals := h.totals[rule.Type+"/"+rule.Unit]
+
+	day, _ := time.Parse("2006-01-02", h.today)
+	streak := 0
+	for ; ; day = day.AddDate(0, 0, -1) {
+		date := day.Format("2006-01-02")
+		target, ok := h.dailyTarget(rule, date)
+		if ok && totals[date] >= target {
+			streak++
+			continue
+		}
+		if date == h.today {
+			continue // Today may still get there
+		}
+		return streak
+	}
+}
+
+// dailyTarget is the target of the user's daily goal for the rule's type on
+// date. Without one, the summary's default goal applies where there is one.
+func (h *History) dailyTarget(rule Rule, date string) (float64, bool) {
+	for i := range h.goals {
+		goal := &h.goals[i]
+		if goal.Period == "daily" && goal.Type == rule.Type && goal.Unit == rule.Unit && goal.ActiveOn(date) {
+			return goal.Target, true
+		}
+	}
+
+	defaults := models.DefaultActivityGoals
+	switch rule.Type + "/" + rule.Unit {
+	case "steps/steps":
+		return float64(defaults.Steps), true
+	case "water/glasses":
+		return float64(defaults.Water), true
+	case "sleep/hours":
+		return defaults.Sleep, true
+	}
+	return 0, false
+}
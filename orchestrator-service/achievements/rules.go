@@ -0,0 +1,79 @@
+package achievements
+
+// DefaultRules are the badges users can earn, in the order they are shown.
+var DefaultRules = []Rule{
+	{
+		ID:          "first_10k_day",
+		Name:        "10K Club",
+		Description: "Walk 10,000 steps in a day",
+		Kind:        Days,
+		Type:        "steps",
+		Unit:        "steps",
+		Threshold:   10000,
+		Target:      1,
+	},
+	{
+		ID:          "steps_streak_3",
+		Name:        "On a Roll",
+		Description: "Reach your daily steps goal 3 days in a row",
+		Kind:        Streak,
+		Type:        "steps",
+		Unit:        "steps",
+		Target:      3,
+	},
+	{
+		ID:          "steps_streak_7",
+		Name:        "Week Walker",
+		Description: "Reach your daily steps goal 7 days in a row",
+		Kind:        Streak,
+		Type:        "steps",
+		Unit:        "steps",
+		Target:      7,
+	},
+	{
+		ID:          "steps_streak_30",
+		Name:        "Unstoppable",
+		Description: "Reach your daily steps goal 30 days in a row",
+		Kind:        Streak,
+		Type:        "steps",
+		Unit:        "steps",
+		Target:      30,
+	},
+	{
+		ID:          "water_streak_7",
+		Name:        "Well Hydrated",
+		Description: "Reach your daily water goal 7 days in a row",
+		Kind:        Streak,
+		Type:        "water",
+		Unit:        "glasses",
+		Target:      7,
+	},
+	{
+		ID:          "sleep_7h_30",
+		Name:        "Well Rested",
+		Description: "Sleep 7 hours or more on 30 nights",
+		Kind:        Days,
+		Type:        "sleep",
+		Unit:        "hours",
+		Threshold:   7,
+		Target:      30,
+	},
+	{
+		ID:          "exercise_1000_min",
+		Name:        "Mover",
+		Description: "Log 1,000 minutes of exercise",
+		Kind:        Total,
+		Type:        "exercise",
+		Unit:        "min",
+		Target:      1000,
+	},
+	{
+		ID:          "steps_1m",
+		Name:        "Millionaire",
+		Description: "Walk 1,000,000 steps in total",
+		Kind:        Total,
+		Type:        "steps",
+		Unit:        "steps",
+		Target:      1000000,
+	},
+}
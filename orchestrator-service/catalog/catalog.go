@@ -0,0 +1,108 @@
+// Package catalog defines the activity types users can log: the units each
+// is measured in, the values that make sense for them and the fields they
+// require.
+package catalog
+
+// Unit is a unit an activity type can be logged in, with the range of
+// values accepted in it (inclusive). AliasOf names the unit this one is
+// another spelling of.
+type Unit struct {
+	Name    string  `json:"name"`
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+	AliasOf string  `json:"aliasOf,omitempty"`
+}
+
+// Type is an activity type users can log.
+type Type struct {
+	Name    string `json:"name"`
+	Label   string `json:"label"`
+	Units   []Unit `json:"units"`   // The first is assumed when none is given
+	Integer bool   `json:"integer"` // Values must be whole numbers
+	// Summable types are measured as totals, such as steps walked, rather
+	// than readings, such as heart rate. Only they can have goals.
+	Summable bool `json:"summable"`
+	// Required lists the fields this type needs besides type, value and
+	// date, which every activity has.
+	Required []string `json:"required,omitempty"`
+}
+
+// Types is the catalog, in the order clients should offer the types.
+var Types = []Type{
+	{
+		Name:     "steps",
+		Label:    "Steps",
+		Units:    []Unit{{Name: "steps", Min: 0, Max: 100000}},
+		Integer:  true,
+		Summable: true,
+	},
+	{
+		Name:    "heart_rate",
+		Label:   "Heart rate",
+		Units:   []Unit{{Name: "bpm", Min: 20, Max: 250}},
+		Integer: true,
+	},
+	{
+		Name:     "water",
+		Label:    "Water",
+		Units:    []Unit{{Name: "glasses", Min: 0, Max: 40}},
+		Summable: true,
+	},
+	{
+		Name:  "sleep",
+		Label: "Sleep",
+		Units: []Unit{
+			{Name: "hours", Min: 0, Max: 24},
+			{Name: "h", Min: 0, Max: 24, AliasOf: "hours"},
+		},
+		Summable: true,
+	},
+	{
+		Name:  "exercise",
+		Label: "Exercise",
+		Units: []Unit{
+			{Name: "min", Min: 0, Max: 1440},
+			{Name: "minutes", Min: 0, Max: 1440, AliasOf: "min"},
+			{Name: "kcal", Min: 0, Max: 10000},
+			{Name: "calories", Min: 0, Max: 10000, AliasOf: "kcal"},
+		},
+		Summable: true,
+		Required: []string{"description"}, // What kind of exercise
+	},
+}
+
+// Lookup finds a type by name.
+func Lookup(name string) (Type, bool) {
+	for _, t := range Types {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Type{}, false
+}
+
+// Unit finds one of the type's units by name.
+func (t Type) Unit(name string) (Unit, bool) {
+	for _, u := range t.Units {
+		if u.Name == name {
+			return u, true
+		}
+	}
+	return Unit{}, false
+}
+
+// Canonical returns the unit that name is an alias of, or name itself.
+func (t Type) Canonical(name string) string {
+	if u, ok := t.Unit(name); ok && u.AliasOf != "" {
+		return u.AliasOf
+	}
+	return name
+}
+
+func (t Type) unitNames() []string {
+	names := make([]string, len(t.Units))
+	for i, u := range t.Units {
+		names[i] = u.Name
+	}
+	return names
+}
@@ -0,0 +1,74 @@
+package catalog
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"orchestrator-service/models"
+)
+
+// FieldError reports what is wrong with one field of an activity. Field
+// is the field's JSON name.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Validate checks an activity against its type in the catalog and returns
+// every problem found, or nil when there are none. A missing unit is set
+// to the type's default.
+func Validate(activity *models.Activity) []FieldError {
+	var errs []FieldError
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if activity.Date.IsZero() {
+		fail("date", "is required")
+	}
+
+	t, ok := Lookup(activity.Type)
+	if !ok {
+		names := make([]string, len(Types))
+		for i, t := range Types {
+			names[i] = t.Name
+		}
+		if activity.Type == "" {
+			fail("type", "is required")
+		} else {
+			fail("type", "must be one of %s", strings.Join(names, ", "))
+		}
+		return errs
+	}
+
+	if activity.Unit == "" {
+		activity.Unit = t.Units[0].Name
+	}
+	unit, ok := t.Unit(activity.Unit)
+	if !ok {
+		fail("unit", "must be one of %s for %s", strings.Join(t.unitNames(), ", "), t.Name)
+	}
+
+	switch value := activity.Value; {
+	case math.IsNaN(value) || math.IsInf(value, 0):
+		fail("value", "must be a number")
+	case ok && (value < unit.Min || value > unit.Max):
+		fail("value", "must be between %s and %s %s", formatValue(unit.Min), formatValue(unit.Max), unit.Name)
+	case t.Integer && value != math.Trunc(value):
+		fail("value", "must be a whole number")
+	}
+
+	for _, field := range t.Required {
+		if field == "description" && strings.TrimSpace(activity.Description) == "" {
+			fail(field, "is required for %s", t.Name)
+		}
+	}
+
+	return errs
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
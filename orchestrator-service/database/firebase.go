@@ -2,26 +2,31 @@ package database
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 
 	"cloud.google.com/go/firestore"
 	firebase "firebase.google.com/go"
+	"firebase.google.com/go/auth"
 	"google.golang.org/api/option"
 )
 
-var (
-	FirebaseApp *firebase.App
-	Client      *firestore.Client
-)
+// ErrInvalidIDToken is returned when a Firebase ID token fails verification.
+var ErrInvalidIDToken = errors.New("invalid ID token")
 
-func InitFirebase() {
-	ctx := context.Background()
+// Firebase holds the Firebase app and its Firestore client.
+type Firebase struct {
+	App    *firebase.App
+	Client *firestore.Client
+}
 
+func InitFirebase(ctx context.Context) (*Firebase, error) {
 	// Use service account key file from environment variable
 	serviceAccountKey := os.Getenv("FIREBASE_SERVICE_ACCOUNT_KEY")
 	if serviceAccountKey == "" {
-		log.Fatal("FIREBASE_SERVICE_ACCOUNT_KEY environment variable is required")
+		return nil, errors.New("FIREBASE_SERVICE_ACCOUNT_KEY environment variable is required")
 	}
 
 	config := &firebase.Config{
@@ -31,22 +36,37 @@ func InitFirebase() {
 	opt := option.WithCredentialsJSON([]byte(serviceAccountKey))
 	app, err := firebase.NewApp(ctx, config, opt)
 	if err != nil {
-		log.Fatalf("error initializing app: %v\n", err)
+		return nil, fmt.Errorf("error initializing app: %w", err)
 	}
 
-	FirebaseApp = app
-
 	client, err := app.Firestore(ctx)
 	if err != nil {
-		log.Fatalf("error initializing Firestore client: %v\n", err)
+		return nil, fmt.Errorf("error initializing Firestore client: %w", err)
 	}
 
-	Client = client
 	log.Println("Connected to Firebase Firestore!")
+	return &Firebase{App: app, Client: client}, nil
+}
+
+// VerifyGoogleToken verifies a Firebase ID token and returns the account it
+// was issued for.
+func (f *Firebase) VerifyGoogleToken(ctx context.Context, idToken string) (*auth.UserRecord, error) {
+	authClient, err := f.App.Auth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize auth client: %w", err)
+	}
+
+	token, err := authClient.VerifyIDToken(ctx, idToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	return authClient.GetUser(ctx, token.UID)
 }
 
-func CloseFirebase() {
-	if Client != nil {
-		Client.Close()
+func (f *Firebase) Close() error {
+	if f.Client != nil {
+		return f.Client.Close()
 	}
+	return nil
 }
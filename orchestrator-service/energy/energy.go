@@ -0,0 +1,118 @@
+// Package energy estimates the energy users spend, from their profile and
+// the activities they log. The figures come from population formulas, not
+// measurements, and are meant as a guide.
+package energy
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"orchestrator-service/models"
+)
+
+// Averages assumed for profile fields the user has not filled in
+const (
+	defaultWeight = 70.0  // kg
+	defaultHeight = 170.0 // cm
+	defaultAge    = 30
+)
+
+// walkingCost is the net energy walking takes, in kcal per kg of body
+// weight per km. It varies little with pace at everyday walking speeds.
+const walkingCost = 0.5
+
+// Genders the formulas tell apart
+const (
+	Male   = "male"
+	Female = "female"
+)
+
+// Profile holds the body measurements the estimates are based on.
+type Profile struct {
+	Weight float64 `json:"weight"` // kg
+	Height float64 `json:"height"` // cm
+	Age    int     `json:"age"`
+	Gender string  `json:"gender,omitempty"` // Male, Female or empty when unknown
+	// Assumed lists the profile fields that were missing and replaced by
+	// averages: "weight", "height" or "dateOfBirth".
+	Assumed []string `json:"assumed,omitempty"`
+}
+
+// NewProfile takes the user's measurements at now, falling back to
+// averages for those not set.
+func NewProfile(user *models.User, now time.Time) Profile {
+	p := Profile{
+		Weight: user.Weight,
+		Height: user.Height,
+		Age:    user.Age(now),
+		Gender: normalizeGender(user.Gender),
+	}
+	if p.Weight <= 0 {
+		p.Weight = defaultWeight
+		p.Assumed = append(p.Assumed, "weight")
+	}
+	if p.Height <= 0 {
+		p.Height = defaultHeight
+		p.Assumed = append(p.Assumed, "height")
+	}
+	if p.Age <= 0 {
+		p.Age = defaultAge
+		p.Assumed = append(p.Assumed, "dateOfBirth")
+	}
+	return p
+}
+
+func normalizeGender(gender string) string {
+	switch strings.ToLower(strings.TrimSpace(gender)) {
+	case "male", "m", "man":
+		return Male
+	case "female", "f", "woman":
+		return Female
+	}
+	return ""
+}
+
+// BMR is the basal metabolic rate in kcal per day, by the Mifflin-St Jeor
+// equation. Without a known gender it takes the midpoint of the male and
+// female equations.
+func (p Profile) BMR() float64 {
+	bmr := 10*p.Weight + 6.25*p.Height - 5*float64(p.Age)
+	switch p.Gender {
+	case Male:
+		return bmr + 5
+	case Female:
+		return bmr - 161
+	}
+	return bmr - 78
+}
+
+// StrideLength is the length of a walking step in metres, estimated from
+// height.
+func (p Profile) StrideLength() float64 {
+	ratio := 0.414
+	switch p.Gender {
+	case Male:
+		ratio = 0.415
+	case Female:
+		ratio = 0.413
+	}
+	return p.Height / 100 * ratio
+}
+
+// Distance is how far the steps take the user, in km.
+func (p Profile) Distance(steps float64) float64 {
+	return steps * p.StrideLength() / 1000
+}
+
+// WalkingCalories is the energy spent walking the steps, in kcal on top
+// of what the body burns at rest.
+func (p Profile) WalkingCalories(steps float64) float64 {
+	return walkingCost * p.Weight * p.Distance(steps)
+}
+
+// ExerciseCalories is the energy spent on minutes of exercise at the given
+// MET, in kcal on top of what the body burns at rest (1 MET).
+func (p Profile) ExerciseCalories(met, minutes float64) float64 {
+	return math.Max(met-1, 0) * p.Weight * minutes / 60
+}
@@ -0,0 +1,98 @@
+package energy
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"orchestrator-service/catalog"
+	"orchestrator-service/models"
+)
+
+// Exercise is a kind of exercise and its intensity in METs, multiples of
+// the energy spent at rest. Keywords are the word beginnings that identify
+// it in an activity's description.
+type Exercise struct {
+	Name     string   `json:"name"`
+	MET      float64  `json:"met"`
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// Exercises are matched against descriptions in order, so more specific
+// kinds come first. Values follow the Compendium of Physical Activities
+// for a moderate effort.
+var Exercises = []Exercise{
+	{Name: "hiit", MET: 8.0, Keywords: []string{"hiit", "interval", "circuit", "crossfit", "tabata"}},
+	{Name: "running", MET: 9.8, Keywords: []string{"run", "sprint", "marathon"}},
+	{Name: "jogging", MET: 7.0, Keywords: []string{"jog"}},
+	{Name: "cycling", MET: 7.5, Keywords: []string{"cycl", "bike", "biking", "spin"}},
+	{Name: "swimming", MET: 6.0, Keywords: []string{"swim"}},
+	{Name: "rowing", MET: 7.0, Keywords: []string{"row"}},
+	{Name: "hiking", MET: 6.0, Keywords: []string{"hik", "trek"}},
+	{Name: "climbing", MET: 8.0, Keywords: []string{"climb", "boulder"}},
+	{Name: "jumping rope", MET: 11.0, Keywords: []string{"skipping", "jump rope", "rope"}},
+	{Name: "skiing", MET: 7.0, Keywords: []string{"ski"}},
+	{Name: "boxing", MET: 7.8, Keywords: []string{"box", "kickbox", "martial", "karate", "judo"}},
+	{Name: "football", MET: 7.0, Keywords: []string{"football", "soccer"}},
+	{Name: "basketball", MET: 6.5, Keywords: []string{"basketball"}},
+	{Name: "tennis", MET: 7.3, Keywords: []string{"tennis", "squash", "badminton"}},
+	{Name: "dancing", MET: 5.0, Keywords: []string{"danc", "zumba"}},
+	{Name: "elliptical", MET: 5.0, Keywords: []string{"elliptical", "cross trainer"}},
+	{Name: "strength", MET: 5.0, Keywords: []string{"strength", "weight", "lift", "gym", "resistance", "squat", "push"}},
+	{Name: "walking", MET: 3.5, Keywords: []string{"walk"}},
+	{Name: "pilates", MET: 3.0, Keywords: []string{"pilates"}},
+	{Name: "yoga", MET: 2.5, Keywords: []string{"yoga"}},
+	{Name: "stretching", MET: 2.3, Keywords: []string{"stretch", "mobility"}},
+}
+
+// DefaultExercise is assumed for descriptions that match no kind.
+var DefaultExercise = Exercise{Name: "other", MET: 4.0}
+
+// MatchExercise finds the kind of exercise a description names.
+func MatchExercise(description string) Exercise {
+	words := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	text := strings.Join(words, " ")
+
+	for _, exercise := range Exercises {
+		for _, keyword := range exercise.Keywords {
+			if strings.Contains(keyword, " ") {
+				if strings.Contains(text, keyword) {
+					return exercise
+				}
+				continue
+			}
+			for _, word := range words {
+				if strings.HasPrefix(word, keyword) {
+					return exercise
+				}
+			}
+		}
+	}
+	return DefaultExercise
+}
+
+// Estimate works out the derived figures of an exercise activity: the kind
+// of exercise and the active calories, from its MET when logged in minutes
+// or as logged in kcal. Other activities have none and get nil.
+func Estimate(activity models.Activity, p Profile) *models.ActivityEstimate {
+	if activity.Type != "exercise" {
+		return nil
+	}
+
+	exercise := MatchExercise(activity.Description)
+	estimate := &models.ActivityEstimate{Exercise: exercise.Name, MET: exercise.MET}
+
+	unit := activity.Unit
+	if t, ok := catalog.Lookup(activity.Type); ok {
+		unit = t.Canonical(unit)
+	}
+	switch unit {
+	case "min":
+		estimate.Calories = math.Round(p.ExerciseCalories(exercise.MET, activity.Value))
+	case "kcal":
+		estimate.Calories = activity.Value
+	}
+	return estimate
+}
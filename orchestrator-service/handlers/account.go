@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"orchestrator-service/mail"
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+	"orchestrator-service/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	passwordResetTTL     = time.Hour
+	emailVerificationTTL = 48 * time.Hour
+
+	// mailTimeout bounds how long a request waits for the mail server.
+	mailTimeout = 15 * time.Second
+)
+
+// ForgotPassword emails a password reset link. It answers the same way
+// whether or not the address has an account, so it cannot be used to
+// discover registered emails.
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	response := gin.H{"message": "If an account exists for that email, a reset link has been sent"}
+
+	user, err := h.store.Users.GetByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusOK, response)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// Google accounts have no password to reset
+	if user.Provider == "google" {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	token, err := h.createActionToken(ctx, user, utils.PurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create reset token"})
+		return
+	}
+
+	link := h.AppURL + "/reset-password?token=" + url.QueryEscape(token)
+	sendCtx, cancel := context.WithTimeout(ctx, mailTimeout)
+	defer cancel()
+	err = h.Mailer.Send(sendCtx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your Health Advisor password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %d minutes and can be used once.\n\n%s\n\nIf you did not ask for this, you can ignore this email.\n",
+			user.FullName, int(passwordResetTTL.Minutes()), link),
+	})
+	if err != nil {
+		// Answered like any other request, or a failure would reveal the account
+		log.Printf("Could not send password reset email: %v", err)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResetPassword sets a new password from a reset token and signs the user
+// out everywhere.
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+
+	token, ok := h.consumeActionToken(c, ctx, utils.PurposePasswordReset, req.Token)
+	if !ok {
+		return
+	}
+
+	user, err := h.store.Users.Get(ctx, token.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not hash password"})
+		return
+	}
+
+	user.Password = hashedPassword
+	// Receiving the email proves ownership of the address
+	if user.Email == token.Email {
+		user.EmailVerified = true
+	}
+	user.UpdatedAt = time.Now()
+
+	if err := h.store.Users.Save(ctx, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not update password"})
+		return
+	}
+
+	if err := h.store.RefreshTokens.RevokeAllForUser(ctx, user.ID, time.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not sign out other sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset"})
+}
+
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	ctx := context.Background()
+
+	token, ok := h.consumeActionToken(c, ctx, utils.PurposeEmailVerification, c.Query("token"))
+	if !ok {
+		return
+	}
+
+	user, err := h.store.Users.Get(ctx, token.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+
+	// The address may have changed since the link was sent
+	if user.Email != token.Email {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+
+	user.EmailVerified = true
+	user.UpdatedAt = time.Now()
+
+	if err := h.store.Users.Save(ctx, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not verify email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+func (h *Handler) ResendVerificationEmail(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+
+	ctx := context.Background()
+
+	user, err := h.store.Users.Get(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch user"})
+		return
+	}
+
+	if user.EmailVerified {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email is already verified"})
+		return
+	}
+
+	if err := h.sendVerificationEmail(ctx, user); err != nil {
+		log.Printf("Could not send verification email: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not send verification email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
+
+func (h *Handler) sendVerificationEmail(ctx context.Context, user *models.User) error {
+	token, err := h.createActionToken(ctx, user, utils.PurposeEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := h.APIURL + "/api/auth/verify-email?token=" + url.QueryEscape(token)
+	ctx, cancel := context.WithTimeout(ctx, mailTimeout)
+	defer cancel()
+	return h.Mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your Health Advisor email",
+		Body:    fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n", user.FullName, link),
+	})
+}
+
+// createActionToken stores a new single-use token for the user and returns
+// the signed value to put in the emailed link.
+func (h *Handler) createActionToken(ctx context.Context, user *models.User, purpose string, ttl time.Duration) (string, error) {
+	secret, err := utils.GenerateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	err = h.store.ActionTokens.Create(ctx, &models.ActionToken{
+		ID:        utils.HashToken(secret),
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Email:     user.Email,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return utils.SignActionToken(purpose, secret), nil
+}
+
+// consumeActionToken validates and uses up a token, writing the error
+// response itself when the token is not acceptable.
+func (h *Handler) consumeActionToken(c *gin.Context, ctx context.Context, purpose, value string) (*models.ActionToken, bool) {
+	secret, ok := utils.VerifyActionToken(purpose, value)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+		return nil, false
+	}
+
+	token, err := h.store.ActionTokens.Consume(ctx, utils.HashToken(secret), time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, false
+	}
+
+	if token.Purpose != purpose || time.Now().After(token.ExpiresAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+		return nil, false
+	}
+
+	return token, true
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"orchestrator-service/achievements"
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type badgeStatus struct {
+	achievements.Status
+	Earned   bool       `json:"earned"`
+	EarnedAt *time.Time `json:"earnedAt,omitempty"`
+}
+
+// GetAchievements lists every badge, earned or locked, with the user's
+// progress towards it. For streak badges the progress is the current
+// streak. Badges are awarded when activities and goals are written, not
+// here.
+func (h *Handler) GetAchievements(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	statuses, earnedAt, err := h.evaluateAchievements(context.Background(), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch achievements"})
+		return
+	}
+
+	earned := 0
+	badges := make([]badgeStatus, len(statuses))
+	for i, status := range statuses {
+		badges[i] = badgeStatus{Status: status}
+		if at, ok := earnedAt[status.ID]; ok {
+			badges[i].Earned = true
+			badges[i].EarnedAt = &at
+			earned++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"badges": badges,
+		"earned": earned,
+		"total":  len(badges),
+	})
+}
+
+// BackfillAchievements awards every user the badges they have already
+// reached, for accounts whose activity was logged before badges were
+// awarded on writes. It runs once at startup; awarding a badge again keeps
+// the original date, so it is safe to repeat.
+func (h *Handler) BackfillAchievements(ctx context.Context) error {
+	users, err := h.store.Users.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range users {
+		if err := h.awardAchievements(ctx, &users[i]); err != nil {
+			return fmt.Errorf("user %s: %w", users[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// checkAchievements awards any badges the user's latest activity or goal
+// change earned. It runs after the change is stored, so failures are only
+// logged.
+func (h *Handler) checkAchievements(ctx context.Context, userID string) {
+	user, err := h.store.Users.Get(ctx, userID)
+	if err == nil {
+		err = h.awardAchievements(ctx, user)
+	}
+	if err != nil {
+		log.Printf("Could not check achievements for user %s: %v", userID, err)
+	}
+}
+
+// awardAchievements records the badges the user has newly reached.
+func (h *Handler) awardAchievements(ctx context.Context, user *models.User) error {
+	statuses, earnedAt, err := h.evaluateAchievements(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, status := range statuses {
+		if _, ok := earnedAt[status.ID]; ok || !status.Reached() {
+			continue
+		}
+		err := h.store.Achievements.Award(ctx, &models.Achievement{
+			UserID:   user.ID,
+			BadgeID:  status.ID,
+			EarnedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+		log.Printf("User %s earned badge %s", user.ID, status.ID)
+	}
+	return nil
+}
+
+// evaluateAchievements evaluates every badge against the user's daily
+// rollups and goals, and returns when each badge already awarded was
+// earned.
+func (h *Handler) evaluateAchievements(ctx context.Context, user *models.User) ([]achievements.Status, map[string]time.Time, error) {
+	if err := h.ensureRollups(ctx, user); err != nil {
+		return nil, nil, err
+	}
+	rollups, err := h.store.Rollups.List(ctx, repository.ActivityRollupFilter{UserID: user.ID})
+	if err != nil {
+		return nil, nil, err
+	}
+	goals, err := h.userGoals(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	earned, err := h.store.Achievements.List(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	earnedAt := make(map[string]time.Time, len(earned))
+	for _, achievement := range earned {
+		earnedAt[achievement.BadgeID] = achievement.EarnedAt
+	}
+
+	history := achievements.NewHistory(rollups, goals, user.Location(), time.Now())
+	return achievements.Evaluate(achievements.DefaultRules, history), earnedAt, nil
+}
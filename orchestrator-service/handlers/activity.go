@@ -2,55 +2,92 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"log"
+	"math"
 	"net/http"
 	"time"
 
-	"orchestrator-service/database"
+	"orchestrator-service/catalog"
+	"orchestrator-service/energy"
 	"orchestrator-service/models"
+	"orchestrator-service/repository"
 	"orchestrator-service/utils"
 
 	"github.com/gin-gonic/gin"
-	"google.golang.org/api/iterator"
 )
 
-func GetActivity(c *gin.Context) {
+// GetActivity lists the user's activities from one day to another
+// (YYYY-MM-DD, both inclusive), today by default, optionally of one type.
+// Days are those of the user's time zone, and each page groups its
+// activities by day; a day cut by the page limit continues on the next page.
+func (h *Handler) GetActivity(c *gin.Context) {
 	userID := c.MustGet("userId").(string)
 
+	limit, cursor, ok := pageParams(c, 50)
+	if !ok {
+		return
+	}
+
 	ctx := context.Background()
 
-	// Get today's activities
-	today := time.Now().Truncate(24 * time.Hour)
-	tomorrow := today.Add(24 * time.Hour)
-
-	iter := database.Client.Collection("activities").
-		Where("userId", "==", userID).
-		Where("date", ">=", today).
-		Where("date", "<", tomorrow).
-		Documents(ctx)
-
-	var activities []models.Activity
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch activities"})
+	user, err := h.store.Users.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch user"})
+		return
+	}
 
-		var activity models.Activity
-		if err := doc.DataTo(&activity); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not decode activities"})
-			return
-		}
-		activities = append(activities, activity)
+	loc := user.Location()
+	today := startOfDay(time.Now(), loc)
+
+	from, err := parseDateParamIn(c.Query("from"), today, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from parameter, expected YYYY-MM-DD"})
+		return
+	}
+	to, err := parseDateParamIn(c.Query("to"), from, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to parameter, expected YYYY-MM-DD"})
+		return
+	}
+	to = to.AddDate(0, 0, 1) // Make the last day inclusive
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
 	}
 
-	c.JSON(http.StatusOK, activities)
+	activities, err := h.store.Activities.List(ctx, repository.ActivityFilter{
+		UserID: userID,
+		Type:   c.Query("type"),
+		From:   from,
+		To:     to,
+		Limit:  limit + 1,
+		After:  cursor,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch activities"})
+		return
+	}
+
+	page := newPage(activities, limit, activityCursor)
+	c.JSON(http.StatusOK, models.Page[models.ActivityDay]{
+		Items:      groupByDay(withEstimates(page.Items, user), loc),
+		NextCursor: page.NextCursor,
+		HasMore:    page.HasMore,
+	})
 }
 
-func CreateActivity(c *gin.Context) {
+// GetActivityTypes lists the activity types that can be logged, with
+// their units and accepted values.
+func (h *Handler) GetActivityTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, catalog.Types)
+}
+
+func (h *Handler) CreateActivity(c *gin.Context) {
 	userID := c.MustGet("userId").(string)
 
 	var activity models.Activity
@@ -59,35 +96,341 @@ func CreateActivity(c *gin.Context) {
 		return
 	}
 
+	if errs := catalog.Validate(&activity); errs != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity", "fields": errs})
+		return
+	}
+
 	activity.ID = utils.GenerateID()
 	activity.UserID = userID
 	activity.CreatedAt = time.Now()
 
 	ctx := context.Background()
 
-	_, err := database.Client.Collection("activities").Doc(activity.ID).Set(ctx, activity)
-	if err != nil {
+	if err := h.store.Activities.Create(ctx, &activity); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create activity"})
 		return
 	}
+	h.refreshRollups(ctx, userID, activity.Date)
+	h.checkAchievements(ctx, userID)
+	if activity.Type == "heart_rate" {
+		h.checkHeartRate(ctx, userID, activity.Date)
+	}
+	h.estimateActivity(ctx, &activity)
 
 	c.JSON(http.StatusCreated, activity)
 }
 
-func GetActivitySummary(c *gin.Context) {
-	// Mock data - you can implement real aggregation later
+// UpdateActivity replaces an activity's type, value, unit, description and
+// date. Without a date the original one is kept.
+func (h *Handler) UpdateActivity(c *gin.Context) {
+	var req models.Activity
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	activity, ok := h.ownedActivity(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	previous := *activity
+	activity.Type = req.Type
+	activity.Value = req.Value
+	activity.Unit = req.Unit
+	activity.Description = req.Description
+	if !req.Date.IsZero() {
+		activity.Date = req.Date
+	}
+
+	h.saveActivity(c, activity, previous)
+}
+
+// PatchActivity changes only the fields present in the request. Changing
+// the type without giving a unit resets the unit to the type's default.
+func (h *Handler) PatchActivity(c *gin.Context) {
+	var patch models.ActivityPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	activity, ok := h.ownedActivity(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	previous := *activity
+	if patch.Type != nil && *patch.Type != activity.Type {
+		activity.Type = *patch.Type
+		activity.Unit = ""
+	}
+	if patch.Value != nil {
+		activity.Value = *patch.Value
+	}
+	if patch.Unit != nil {
+		activity.Unit = *patch.Unit
+	}
+	if patch.Description != nil {
+		activity.Description = *patch.Description
+	}
+	if patch.Date != nil {
+		activity.Date = *patch.Date
+	}
+
+	h.saveActivity(c, activity, previous)
+}
+
+func (h *Handler) DeleteActivity(c *gin.Context) {
+	activity, ok := h.ownedActivity(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+
+	if err := h.store.Activities.Delete(ctx, activity.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not delete activity"})
+		return
+	}
+	h.refreshRollups(ctx, activity.UserID, activity.Date)
+	if activity.Type == "heart_rate" {
+		h.checkHeartRate(ctx, activity.UserID, activity.Date)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Activity deleted successfully"})
+}
+
+// ownedActivity fetches an activity, answering 404 or 403 itself when it
+// does not exist or belongs to someone else.
+func (h *Handler) ownedActivity(c *gin.Context, id string) (*models.Activity, bool) {
+	userID := c.MustGet("userId").(string)
+
+	activity, err := h.store.Activities.Get(context.Background(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch activity"})
+		return nil, false
+	}
+
+	// Verify the activity belongs to the user
+	if activity.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return nil, false
+	}
+
+	return activity, true
+}
+
+// saveActivity validates and stores an edited activity, then brings the
+// rollups of its old and new day up to date and checks for newly earned
+// badges and, if it is or was a heart rate reading, unusual readings.
+// Summaries are computed from the stored activities on every request and
+// need nothing.
+func (h *Handler) saveActivity(c *gin.Context, activity *models.Activity, previous models.Activity) {
+	if errs := catalog.Validate(activity); errs != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity", "fields": errs})
+		return
+	}
+
+	ctx := context.Background()
+
+	if err := h.store.Activities.Update(ctx, activity); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not update activity"})
+		return
+	}
+	h.refreshRollups(ctx, activity.UserID, previous.Date, activity.Date)
+	h.checkAchievements(ctx, activity.UserID)
+	if activity.Type == "heart_rate" || previous.Type == "heart_rate" {
+		h.checkHeartRate(ctx, activity.UserID, previous.Date, activity.Date)
+	}
+	h.estimateActivity(ctx, activity)
+
+	c.JSON(http.StatusOK, activity)
+}
+
+func (h *Handler) GetActivitySummary(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+
+	ctx := context.Background()
+
+	user, err := h.store.Users.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch user"})
+		return
+	}
+
+	// Fetch the trailing week, today included
+	today := startOfDay(time.Now(), user.Location())
+	weekStart := today.AddDate(0, 0, -6)
+
+	activities, err := h.store.Activities.List(ctx, repository.ActivityFilter{
+		UserID: userID,
+		From:   weekStart,
+		To:     today.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch activities"})
+		return
+	}
+
+	goals, err := h.userGoals(ctx, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch goals"})
+		return
+	}
+	targets := dailyTargets(goals, localDate(today, user.Location()))
+
+	c.JSON(http.StatusOK, summarizeActivities(activities, targets, energy.NewProfile(user, time.Now()), today))
+}
+
+// summarizeActivities aggregates a week of activities ending on the day
+// starting at today, a midnight in the user's time zone. Distance and
+// calories are estimated for today from the user's profile.
+func summarizeActivities(activities []models.Activity, goals models.ActivityGoals, profile energy.Profile, today time.Time) models.ActivitySummary {
 	summary := models.ActivitySummary{
-		Steps:          8432,
-		StepsGoal:      10000,
-		HeartRate:      72,
-		Water:          6,
-		WaterGoal:      8,
-		Sleep:          7.5,
-		SleepGoal:      8,
-		TotalSteps:     67845,
-		ActiveMinutes:  245,
-		CaloriesBurned: 2840,
-	}
-
-	c.JSON(http.StatusOK, summary)
+		StepsGoal: goals.Steps,
+		WaterGoal: goals.Water,
+		SleepGoal: goals.Sleep,
+	}
+
+	tomorrow := today.AddDate(0, 0, 1)
+
+	var (
+		steps, totalSteps   float64
+		water, totalWater   float64
+		sleep, totalSleep   float64
+		activeMinutes, kcal float64
+		heartRateSum        float64
+		heartRateCount      int
+		latestHeartRate     models.Activity
+		hasLatestHeartRate  bool
+	)
+
+	for _, activity := range activities {
+		isToday := !activity.Date.Before(today) && activity.Date.Before(tomorrow)
+
+		switch activity.Type {
+		case "steps":
+			totalSteps += activity.Value
+			if isToday {
+				steps += activity.Value
+			}
+		case "water":
+			totalWater += activity.Value
+			if isToday {
+				water += activity.Value
+			}
+		case "sleep":
+			totalSleep += activity.Value
+			if isToday {
+				sleep += activity.Value
+			}
+		case "heart_rate":
+			heartRateSum += activity.Value
+			heartRateCount++
+			if !hasLatestHeartRate || activity.Date.After(latestHeartRate.Date) {
+				latestHeartRate = activity
+				hasLatestHeartRate = true
+			}
+		case "exercise":
+			if !isToday {
+				continue
+			}
+			if activity.Unit == "min" || activity.Unit == "minutes" {
+				activeMinutes += activity.Value
+			}
+			if estimate := energy.Estimate(activity, profile); estimate != nil {
+				kcal += estimate.Calories
+			}
+		}
+	}
+
+	summary.Steps = int(math.Round(steps))
+	summary.TotalSteps = int(math.Round(totalSteps))
+	summary.Water = int(math.Round(water))
+	summary.TotalWater = int(math.Round(totalWater))
+	summary.Sleep = sleep
+	summary.TotalSleep = totalSleep
+	summary.ActiveMinutes = int(math.Round(activeMinutes))
+
+	if hasLatestHeartRate {
+		summary.HeartRate = int(math.Round(latestHeartRate.Value))
+		summary.AvgHeartRate = int(math.Round(heartRateSum / float64(heartRateCount)))
+	}
+
+	walking := profile.WalkingCalories(steps)
+	bmr := profile.BMR()
+	summary.Distance = math.Round(profile.Distance(steps)*100) / 100
+	summary.CaloriesBurned = int(math.Round(walking + kcal))
+	summary.Energy = models.EnergyEstimate{
+		Weight:           profile.Weight,
+		Height:           profile.Height,
+		Age:              profile.Age,
+		Gender:           profile.Gender,
+		Assumed:          profile.Assumed,
+		BMR:              int(math.Round(bmr)),
+		StrideLength:     math.Round(profile.StrideLength()*100) / 100,
+		WalkingCalories:  int(math.Round(walking)),
+		ExerciseCalories: int(math.Round(kcal)),
+		TotalCalories:    int(math.Round(bmr + walking + kcal)),
+	}
+
+	return summary
+}
+
+// withEstimates fills in the derived figures of exercise activities from
+// the user's profile.
+func withEstimates(activities []models.Activity, user *models.User) []models.Activity {
+	profile := energy.NewProfile(user, time.Now())
+	for i := range activities {
+		activities[i].Estimate = energy.Estimate(activities[i], profile)
+	}
+	return activities
+}
+
+// estimateActivity fills in the derived figures of an activity about to be
+// returned. Without the user's profile it is returned without them.
+func (h *Handler) estimateActivity(ctx context.Context, activity *models.Activity) {
+	activity.Estimate = nil
+	user, err := h.store.Users.Get(ctx, activity.UserID)
+	if err != nil {
+		log.Printf("Could not estimate activity %s: %v", activity.ID, err)
+		return
+	}
+	activity.Estimate = energy.Estimate(*activity, energy.NewProfile(user, time.Now()))
+}
+
+// groupByDay splits activities ordered by date into the days of loc they
+// fall on.
+func groupByDay(activities []models.Activity, loc *time.Location) []models.ActivityDay {
+	days := []models.ActivityDay{}
+	for _, activity := range activities {
+		date := localDate(activity.Date, loc)
+		if n := len(days); n > 0 && days[n-1].Date == date {
+			days[n-1].Activities = append(days[n-1].Activities, activity)
+			continue
+		}
+		days = append(days, models.ActivityDay{Date: date, Activities: []models.Activity{activity}})
+	}
+	return days
+}
+
+// startOfDay is midnight at the start of t's day in loc.
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	year, month, day := t.In(loc).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// localDate is t's day in loc as YYYY-MM-DD.
+func localDate(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("2006-01-02")
 }
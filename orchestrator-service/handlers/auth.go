@@ -2,18 +2,21 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"io"
+	"log"
 	"net/http"
 	"time"
 
 	"orchestrator-service/database"
 	"orchestrator-service/models"
+	"orchestrator-service/repository"
 	"orchestrator-service/utils"
 
 	"github.com/gin-gonic/gin"
-	"google.golang.org/api/iterator"
 )
 
-func Login(c *gin.Context) {
+func (h *Handler) Login(c *gin.Context) {
 	var req models.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -21,12 +24,9 @@ func Login(c *gin.Context) {
 	}
 
 	ctx := context.Background()
-	iter := database.Client.Collection("users").Where("email", "==", req.Email).Limit(1).Documents(ctx)
-	defer iter.Stop()
-
-	doc, err := iter.Next()
+	user, err := h.store.Users.GetByEmail(ctx, req.Email)
 	if err != nil {
-		if err == iterator.Done {
+		if errors.Is(err, repository.ErrNotFound) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 			return
 		}
@@ -34,12 +34,6 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	var user models.User
-	if err := doc.DataTo(&user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
-	}
-
 	// For Google users, don't allow password login
 	if user.Provider == "google" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Please use Google Sign-In"})
@@ -51,24 +45,10 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	token, err := utils.GenerateToken(user.ID, user.Email)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate token"})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Login successful",
-		"token":   token,
-		"user": gin.H{
-			"id":       user.ID,
-			"email":    user.Email,
-			"fullName": user.FullName,
-		},
-	})
+	h.completeLogin(c, user, req.DeviceID, http.StatusOK, "Login successful")
 }
 
-func Register(c *gin.Context) {
+func (h *Handler) Register(c *gin.Context) {
 	var req models.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -78,13 +58,15 @@ func Register(c *gin.Context) {
 	ctx := context.Background()
 
 	// Check if user already exists
-	iter := database.Client.Collection("users").Where("email", "==", req.Email).Limit(1).Documents(ctx)
-	defer iter.Stop()
-
-	if _, err := iter.Next(); err == nil {
+	_, err := h.store.Users.GetByEmail(ctx, req.Email)
+	if err == nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "User already exists"})
 		return
 	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
 
 	// Hash password
 	hashedPassword, err := utils.HashPassword(req.Password)
@@ -109,30 +91,25 @@ func Register(c *gin.Context) {
 		},
 	}
 
-	_, err = database.Client.Collection("users").Doc(user.ID).Set(ctx, user)
-	if err != nil {
+	if err := h.store.Users.Save(ctx, &user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create user"})
 		return
 	}
 
-	token, err := utils.GenerateToken(user.ID, user.Email)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate token"})
-		return
+	// A failed email should not fail the sign-up; the user can ask again
+	if err := h.sendVerificationEmail(ctx, &user); err != nil {
+		log.Printf("Could not send verification email: %v", err)
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "User created successfully",
-		"token":   token,
-		"user": gin.H{
-			"id":       user.ID,
-			"email":    user.Email,
-			"fullName": user.FullName,
-		},
-	})
+	h.completeLogin(c, &user, req.DeviceID, http.StatusCreated, "User created successfully")
 }
 
-func GoogleAuth(c *gin.Context) {
+func (h *Handler) GoogleAuth(c *gin.Context) {
+	if h.Google == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Google Sign-In is not configured"})
+		return
+	}
+
 	var req models.GoogleAuthRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -141,48 +118,35 @@ func GoogleAuth(c *gin.Context) {
 
 	ctx := context.Background()
 
-	// Verify Firebase ID token
-	authClient, err := database.FirebaseApp.Auth(ctx)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to initialize auth client"})
-		return
-	}
-
-	token, err := authClient.VerifyIDToken(ctx, req.Token)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Google token"})
-		return
-	}
-
-	// Get user info from token
-	userRecord, err := authClient.GetUser(ctx, token.UID)
+	// Verify Firebase ID token and get user info from it
+	userRecord, err := h.Google.VerifyGoogleToken(ctx, req.Token)
 	if err != nil {
+		if errors.Is(err, database.ErrInvalidIDToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Google token"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user info"})
 		return
 	}
 
-	// Check if user exists in Firestore
-	iter := database.Client.Collection("users").Where("email", "==", userRecord.Email).Limit(1).Documents(ctx)
-	defer iter.Stop()
-
-	var user models.User
-
-	doc, err := iter.Next()
-	if err != nil && err != iterator.Done {
+	// Check if user exists
+	user, err := h.store.Users.GetByEmail(ctx, userRecord.Email)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
 
-	if err == iterator.Done {
+	if errors.Is(err, repository.ErrNotFound) {
 		// Create new user
-		user = models.User{
-			ID:        userRecord.UID,
-			Email:     userRecord.Email,
-			FullName:  userRecord.DisplayName,
-			Provider:  "google",
-			GoogleID:  userRecord.UID,
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+		user = &models.User{
+			ID:            userRecord.UID,
+			Email:         userRecord.Email,
+			FullName:      userRecord.DisplayName,
+			EmailVerified: userRecord.EmailVerified,
+			Provider:      "google",
+			GoogleID:      userRecord.UID,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
 			Settings: models.UserSettings{
 				EmailNotifications: true,
 				PushNotifications:  true,
@@ -195,37 +159,192 @@ func GoogleAuth(c *gin.Context) {
 			user.FullName = req.FullName
 		}
 
-		_, err = database.Client.Collection("users").Doc(user.ID).Set(ctx, user)
-		if err != nil {
+		if err := h.store.Users.Save(ctx, user); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create user"})
 			return
 		}
-	} else {
-		// Update existing user - we might want to update some fields
-		if err := doc.DataTo(&user); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+	}
+
+	h.completeLogin(c, user, req.DeviceID, http.StatusOK, "Google authentication has been done successfully")
+}
+
+// RefreshToken exchanges a refresh token for a new access token and rotates
+// the refresh token. Presenting a token that was already rotated means it
+// leaked, so the whole login is revoked.
+func (h *Handler) RefreshToken(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+
+	stored, err := h.store.RefreshTokens.Get(ctx, utils.HashToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if stored.Revoked() {
+		h.refreshTokenReused(c, stored, now)
+		return
+	}
+
+	if now.After(stored.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token has expired"})
+		return
+	}
+
+	user, err := h.store.Users.Get(ctx, stored.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	tokens, err := h.issueTokens(c, user, stored.DeviceID, stored.FamilyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate token"})
+		return
+	}
+
+	// Another refresh with the same token may have won since the check
+	// above; the loser counts as reuse, which also revokes the new token
+	newID := utils.HashToken(tokens["refreshToken"].(string))
+	if err := h.store.RefreshTokens.Rotate(ctx, stored.ID, now, newID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.refreshTokenReused(c, stored, now)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// refreshTokenReused answers a refresh with a token that was already
+// rotated. That means it leaked, so the whole login it belongs to is
+// revoked.
+func (h *Handler) refreshTokenReused(c *gin.Context, stored *models.RefreshToken, now time.Time) {
+	if err := h.store.RefreshTokens.RevokeFamily(context.Background(), stored.FamilyID, now); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token has been revoked"})
+}
+
+// Logout revokes the caller's access token and, when given, the refresh
+// token of the same device.
+func (h *Handler) Logout(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+	tokenID := c.MustGet("tokenId").(string)
+	expiresAt := c.MustGet("tokenExpiresAt").(time.Time)
+
+	var req models.LogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+
+	if err := h.store.RevokedTokens.Revoke(ctx, tokenID, expiresAt); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not revoke token"})
+		return
+	}
+
+	if req.RefreshToken != "" {
+		stored, err := h.store.RefreshTokens.Get(ctx, utils.HashToken(req.RefreshToken))
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not revoke token"})
 			return
 		}
+		if err == nil && stored.UserID == userID && !stored.Revoked() {
+			if err := h.store.RefreshTokens.Revoke(ctx, stored.ID, time.Now(), ""); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not revoke token"})
+				return
+			}
+		}
+	}
 
-		// Update the user's information if needed (e.g., profile picture, display name)
-		// For now, we'll just use the existing user data
-		// You could add update logic here if needed
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// completeLogin finishes a successful first-factor sign-in. Accounts with
+// two-factor authentication get a challenge token to exchange through
+// TwoFactorLogin; everyone else gets their tokens right away.
+func (h *Handler) completeLogin(c *gin.Context, user *models.User, deviceID string, status int, message string) {
+	if user.Settings.TwoFactorAuth {
+		challenge, err := utils.GenerateChallengeToken(user.ID, user.Email)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate token"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":           "Two-factor authentication required",
+			"twoFactorRequired": true,
+			"challengeToken":    challenge,
+			"expiresIn":         int(utils.ChallengeTokenTTL.Seconds()),
+		})
+		return
 	}
 
-	// Generate our JWT token
-	jwtToken, err := utils.GenerateToken(user.ID, user.Email)
+	tokens, err := h.issueTokens(c, user, deviceID, "")
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate token"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Google authentication has been done successfully",
-		"token":   jwtToken,
-		"user": gin.H{
-			"id":       user.ID,
-			"email":    user.Email,
-			"fullName": user.FullName,
-		},
+	tokens["message"] = message
+	tokens["user"] = gin.H{
+		"id":       user.ID,
+		"email":    user.Email,
+		"fullName": user.FullName,
+	}
+	c.JSON(status, tokens)
+}
+
+// issueTokens creates an access token and a persisted refresh token for the
+// user. An empty familyID starts a new login.
+func (h *Handler) issueTokens(c *gin.Context, user *models.User, deviceID, familyID string) (gin.H, error) {
+	accessToken, err := utils.GenerateToken(user.ID, user.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, hash, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if familyID == "" {
+		familyID = utils.GenerateID()
+	}
+
+	now := time.Now()
+	err = h.store.RefreshTokens.Create(context.Background(), &models.RefreshToken{
+		ID:        hash,
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		DeviceID:  deviceID,
+		UserAgent: c.Request.UserAgent(),
+		CreatedAt: now,
+		ExpiresAt: now.Add(utils.RefreshTokenTTL),
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return gin.H{
+		"token":        accessToken,
+		"refreshToken": refreshToken,
+		"expiresIn":    int(utils.AccessTokenTTL.Seconds()),
+	}, nil
 }
@@ -1,25 +1,23 @@
 package handlers
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
+	"errors"
+	"log"
+	"math"
 	"net/http"
 	"strconv"
 	"time"
 
-	"orchestrator-service/database"
 	"orchestrator-service/models"
+	"orchestrator-service/rag"
+	"orchestrator-service/repository"
 	"orchestrator-service/utils"
 
-	"cloud.google.com/go/firestore"
 	"github.com/gin-gonic/gin"
-	"google.golang.org/api/iterator"
 )
 
-func SendMessage(c *gin.Context) {
+func (h *Handler) SendMessage(c *gin.Context) {
 	userID := c.MustGet("userId").(string)
 
 	var req models.ChatRequest
@@ -28,231 +26,134 @@ func SendMessage(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
+
+	session, isNew, ok := h.sessionForMessage(c, req.SessionID)
+	if !ok {
+		return
+	}
 
-	// Save user message
 	userMessage := models.ChatMessage{
 		ID:        utils.GenerateID(),
 		UserID:    userID,
 		Text:      req.Message,
 		Sender:    "user",
 		Timestamp: time.Now(),
-		SessionID: req.SessionID,
+		SessionID: session.ID,
 	}
 
-	_, err := database.Client.Collection("chat_messages").Doc(userMessage.ID).Set(ctx, userMessage)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not save message"})
-		return
-	}
+	var aiResponse string
+
+	triaged := h.triage(session, req.Message)
+	if triaged != nil {
+		// Urgent-care guidance instead of an answer from the advisor
+		aiResponse = triaged.Response
+	} else {
+		ragReq, redactor, err := h.ragRequest(ctx, session, req.Message)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not prepare the conversation"})
+			return
+		}
 
-	// Generate AI response
-	aiResponse := generateAIResponse(req.Message, req.History)
+		// Generate AI response. Nothing is stored when this fails, so the
+		// client can simply retry.
+		aiResponse, err = h.RAG.Query(ctx, ragReq)
+		if err != nil {
+			respondRAGError(c, err)
+			return
+		}
+		aiResponse = redactor.Restore(aiResponse)
+	}
 
-	// Save AI response
 	aiMessage := models.ChatMessage{
 		ID:        utils.GenerateID(),
 		UserID:    userID,
 		Text:      aiResponse,
 		Sender:    "ai",
 		Timestamp: time.Now(),
-		SessionID: req.SessionID,
+		SessionID: session.ID,
 	}
+	flagMessages(triaged, &userMessage, &aiMessage)
 
-	_, err = database.Client.Collection("chat_messages").Doc(aiMessage.ID).Set(ctx, aiMessage)
-	if err != nil {
+	if isNew {
+		if err := h.store.ChatSessions.Create(ctx, session); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create chat session"})
+			return
+		}
+	}
+	if err := h.store.ChatMessages.Create(ctx, &userMessage); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not save message"})
+		return
+	}
+	if err := h.store.ChatMessages.Create(ctx, &aiMessage); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not save AI response"})
 		return
 	}
+	if err := h.recordExchange(ctx, session, req.Message, aiMessage.Timestamp); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not update chat session"})
+		return
+	}
 
 	// Return both messages
 	response := models.ChatResponse{
 		UserMessage: userMessage,
 		AiMessage:   aiMessage,
+		Session:     *session,
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
-func GetChatHistory(c *gin.Context) {
+// GetChatHistory pages backwards through the user's messages, optionally
+// in one session: each page is in chronological order and its cursor
+// leads to the messages before it.
+func (h *Handler) GetChatHistory(c *gin.Context) {
 	userID := c.MustGet("userId").(string)
 
 	// Optional: get session ID from query params
 	sessionID := c.Query("sessionId")
 
-	// Optional: limit and pagination
-	limit, err := parseInt(c.Query("limit"), 50)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+	limit, cursor, ok := pageParams(c, 50)
+	if !ok {
 		return
 	}
 
 	ctx := context.Background()
 
-	// Build query - only get messages for this specific user
-	query := database.Client.Collection("chat_messages").Where("userId", "==", userID)
-	if sessionID != "" {
-		query = query.Where("sessionId", "==", sessionID)
-	}
-
-	// Find options - sort by timestamp descending (newest first) and limit
-	iter := query.OrderBy("timestamp", firestore.Desc).Limit(limit).Documents(ctx)
-	defer iter.Stop()
-
-	var messages []models.ChatMessage
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch chat history"})
-			return
-		}
-
-		var message models.ChatMessage
-		if err := doc.DataTo(&message); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not decode chat history"})
-			return
-		}
-		messages = append(messages, message)
-	}
-
-	// Reverse to get chronological order (oldest first)
-	messages = reverseMessages(messages)
-
-	c.JSON(http.StatusOK, gin.H{
-		"messages": messages,
-		"count":    len(messages),
-		"userId":   userID,
-	})
-}
-
-func GetChatSessions(c *gin.Context) {
-	userID := c.MustGet("userId").(string)
-
-	ctx := context.Background()
-
-	// Get all messages for this user to group by session
-	iter := database.Client.Collection("chat_messages").
-		Where("userId", "==", userID).
-		OrderBy("timestamp", firestore.Desc).
-		Documents(ctx)
-	defer iter.Stop()
-
-	// Group messages by session ID manually
-	sessionMap := make(map[string]struct {
-		LastMessage  models.ChatMessage
-		MessageCount int
-		FirstMessage time.Time
+	// Only get messages for this specific user, newest first
+	messages, err := h.store.ChatMessages.List(ctx, repository.ChatMessageFilter{
+		UserID:    userID,
+		SessionID: sessionID,
+		Limit:     limit + 1,
+		After:     cursor,
 	})
-
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch chat sessions"})
-			return
-		}
-
-		var message models.ChatMessage
-		if err := doc.DataTo(&message); err != nil {
-			continue // Skip invalid messages
-		}
-
-		sessionID := message.SessionID
-		if sessionID == "" {
-			sessionID = "default" // Handle messages without session ID
-		}
-
-		session, exists := sessionMap[sessionID]
-		if !exists {
-			session = struct {
-				LastMessage  models.ChatMessage
-				MessageCount int
-				FirstMessage time.Time
-			}{
-				LastMessage:  message,
-				MessageCount: 0,
-				FirstMessage: message.Timestamp,
-			}
-		}
-
-		session.MessageCount++
-		if message.Timestamp.After(session.LastMessage.Timestamp) {
-			session.LastMessage = message
-		}
-		if message.Timestamp.Before(session.FirstMessage) {
-			session.FirstMessage = message.Timestamp
-		}
-
-		sessionMap[sessionID] = session
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch chat history"})
+		return
 	}
 
-	// Convert map to slice for response
-	var sessions []map[string]interface{}
-	for sessionID, sessionData := range sessionMap {
-		session := map[string]interface{}{
-			"sessionId":    sessionID,
-			"lastMessage":  sessionData.LastMessage,
-			"messageCount": sessionData.MessageCount,
-			"firstMessage": sessionData.FirstMessage,
-			"lastActivity": sessionData.LastMessage.Timestamp,
-		}
-		sessions = append(sessions, session)
-	}
+	page := newPage(messages, limit, chatMessageCursor)
 
-	// Sort sessions by last activity (newest first)
-	// Simple bubble sort for small datasets - consider better sorting for large datasets
-	for i := 0; i < len(sessions)-1; i++ {
-		for j := 0; j < len(sessions)-i-1; j++ {
-			timeJ := sessions[j]["lastActivity"].(time.Time)
-			timeJ1 := sessions[j+1]["lastActivity"].(time.Time)
-			if timeJ.Before(timeJ1) {
-				sessions[j], sessions[j+1] = sessions[j+1], sessions[j]
-			}
-		}
-	}
+	// Reverse to get chronological order (oldest first)
+	page.Items = reverseMessages(page.Items)
 
-	c.JSON(http.StatusOK, gin.H{
-		"sessions": sessions,
-		"userId":   userID,
-	})
+	c.JSON(http.StatusOK, page)
 }
 
-func generateAIResponse(userMessage string, history []models.ChatHistoryItem) string {
-	payload := map[string]interface{}{
-		"query":   userMessage,
-		"history": history,
-	}
+// respondRAGError maps a failed RAG call to an error response.
+func respondRAGError(c *gin.Context, err error) {
+	log.Printf("RAG request failed: %v", err)
 
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Sprintf("Error encoding JSON: %v", err)
+	var open *rag.CircuitOpenError
+	switch {
+	case errors.As(err, &open):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(open.RetryAfter.Seconds()))))
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "The advisor is temporarily unavailable, please try again shortly"})
+	case errors.Is(err, context.DeadlineExceeded):
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": "The advisor took too long to answer"})
+	default:
+		c.JSON(http.StatusBadGateway, gin.H{"error": "The advisor could not answer right now"})
 	}
-
-	resp, err := http.Post("http://rag-service:8000/query", "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Sprintf("Error sending request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Sprintf("Error reading response: %v", err)
-	}
-
-	var ragResponse struct {
-		Answer string `json:"answer"`
-	}
-
-	if err := json.Unmarshal(body, &ragResponse); err != nil {
-		return string(body)
-	}
-
-	return ragResponse.Answer
 }
 
 // Helper function to reverse message order
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxFeedbackWindows bounds how many buckets one stats request may ask for.
+const maxFeedbackWindows = 400
+
+// SubmitFeedback rates one of the user's AI answers. Rating the same answer
+// again replaces the earlier feedback.
+func (h *Handler) SubmitFeedback(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+	messageID := c.Param("id")
+
+	var req models.ChatFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+
+	message, err := h.store.ChatMessages.Get(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch message"})
+		return
+	}
+
+	// Check ownership
+	if message.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+	if message.Sender != "ai" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only AI answers can be rated"})
+		return
+	}
+
+	now := time.Now()
+	feedback := models.ChatFeedback{
+		MessageID: message.ID,
+		UserID:    userID,
+		SessionID: message.SessionID,
+		Rating:    req.Rating,
+		Reason:    req.Reason,
+		Comment:   strings.TrimSpace(req.Comment),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	existing, err := h.store.ChatFeedback.Get(ctx, message.ID)
+	switch {
+	case err == nil:
+		feedback.CreatedAt = existing.CreatedAt
+	case !errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not save feedback"})
+		return
+	}
+
+	if err := h.store.ChatFeedback.Save(ctx, &feedback); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not save feedback"})
+		return
+	}
+
+	c.JSON(http.StatusOK, feedback)
+}
+
+// GetFeedbackStats reports the caller's own feedback rates; see
+// feedbackStats.
+func (h *Handler) GetFeedbackStats(c *gin.Context) {
+	h.feedbackStats(c, c.MustGet("userId").(string))
+}
+
+// GetAllFeedbackStats reports feedback rates across all users, for
+// operators judging answer quality; see feedbackStats.
+func (h *Handler) GetAllFeedbackStats(c *gin.Context) {
+	h.feedbackStats(c, "")
+}
+
+// feedbackStats reports feedback rates per day, week or month between from
+// and to (YYYY-MM-DD, to inclusive), defaulting to the last 30 days, for one
+// user or, with an empty userID, everyone. Only counts are returned, never
+// comments.
+func (h *Handler) feedbackStats(c *gin.Context, userID string) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	from, err := parseDateParam(c.Query("from"), today.AddDate(0, 0, -29))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from parameter, expected YYYY-MM-DD"})
+		return
+	}
+	to, err := parseDateParam(c.Query("to"), today)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to parameter, expected YYYY-MM-DD"})
+		return
+	}
+	to = to.AddDate(0, 0, 1) // Make the last day inclusive
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	window := c.DefaultQuery("window", "day")
+	windows, err := feedbackWindows(from, to, window)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	feedback, err := h.store.ChatFeedback.List(context.Background(), repository.ChatFeedbackFilter{UserID: userID, From: from, To: to})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch feedback"})
+		return
+	}
+
+	total := aggregateFeedback(feedback, from, to)
+	stats := make([]models.FeedbackStats, len(windows))
+	for i, w := range windows {
+		stats[i] = aggregateFeedback(feedback, w[0], w[1])
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"window":  window,
+		"total":   total,
+		"windows": stats,
+	})
+}
+
+// feedbackWindows splits [from, to) into consecutive windows. The last one
+// is cut short at to.
+func feedbackWindows(from, to time.Time, window string) ([][2]time.Time, error) {
+	var next func(time.Time) time.Time
+	switch window {
+	case "day":
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	case "week":
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	case "month":
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	default:
+		return nil, errors.New("window must be day, week or month")
+	}
+
+	var windows [][2]time.Time
+	for start := from; start.Before(to); start = next(start) {
+		if len(windows) == maxFeedbackWindows {
+			return nil, errors.New("date range too large for this window")
+		}
+
+		end := next(start)
+		if end.After(to) {
+			end = to
+		}
+		windows = append(windows, [2]time.Time{start, end})
+	}
+	return windows, nil
+}
+
+// aggregateFeedback counts the feedback created in [from, to).
+func aggregateFeedback(feedback []models.ChatFeedback, from, to time.Time) models.FeedbackStats {
+	stats := models.FeedbackStats{
+		From:    from,
+		To:      to,
+		Reasons: make(map[string]int),
+	}
+
+	for _, f := range feedback {
+		if f.CreatedAt.Before(from) || !f.CreatedAt.Before(to) {
+			continue
+		}
+
+		stats.Total++
+		switch f.Rating {
+		case "up":
+			stats.Up++
+		case "down":
+			stats.Down++
+			if f.Reason != "" {
+				stats.Reasons[f.Reason]++
+			}
+		}
+	}
+
+	if stats.Total > 0 {
+		stats.PositiveRate = float64(stats.Up) / float64(stats.Total)
+	}
+	return stats
+}
+
+// parseDateParam parses a YYYY-MM-DD query parameter as a UTC date.
+func parseDateParam(value string, defaultValue time.Time) (time.Time, error) {
+	return parseDateParamIn(value, defaultValue, time.UTC)
+}
+
+// parseDateParamIn parses a YYYY-MM-DD query parameter as midnight in loc.
+func parseDateParamIn(value string, defaultValue time.Time, loc *time.Location) (time.Time, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, loc)
+}
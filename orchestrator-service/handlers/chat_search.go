@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"orchestrator-service/repository"
+	"orchestrator-service/search"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSearchCandidates bounds how many matching messages are ranked per
+// search; beyond it only the most recent are considered.
+const maxSearchCandidates = 500
+
+type searchSession struct {
+	SessionID string          `json:"sessionId"`
+	Title     string          `json:"title"`
+	Score     float64         `json:"score"` // Best score in the session
+	Results   []search.Result `json:"results"`
+}
+
+// SearchChat finds the user's messages matching q, best first, grouped by
+// session. Sessions are ordered by their best match.
+func (h *Handler) SearchChat(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter q is required"})
+		return
+	}
+	terms := search.QueryTerms(q)
+	if len(terms) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query has no searchable words"})
+		return
+	}
+
+	limit, err := parseInt(c.Query("limit"), 20)
+	if err != nil || limit < 1 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+		return
+	}
+
+	ctx := context.Background()
+
+	candidates, err := h.store.ChatMessages.Search(ctx, repository.ChatSearchFilter{
+		UserID: userID,
+		Terms:  terms,
+		Limit:  maxSearchCandidates,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not search chat history"})
+		return
+	}
+
+	results := search.Rank(terms, candidates)
+	total := len(results)
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	var sessions []*searchSession
+	bySession := make(map[string]*searchSession)
+	for _, result := range results {
+		group, ok := bySession[result.Message.SessionID]
+		if !ok {
+			group = &searchSession{SessionID: result.Message.SessionID, Score: result.Score}
+			bySession[group.SessionID] = group
+			sessions = append(sessions, group)
+		}
+		group.Results = append(group.Results, result)
+	}
+
+	for _, group := range sessions {
+		if group.SessionID == "" {
+			continue
+		}
+		session, err := h.store.ChatSessions.Get(ctx, group.SessionID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch chat sessions"})
+			return
+		}
+		if session != nil {
+			group.Title = session.Title
+		}
+	}
+
+	if sessions == nil {
+		sessions = []*searchSession{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":    q,
+		"terms":    terms,
+		"total":    total,
+		"sessions": sessions,
+	})
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+	"orchestrator-service/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxTitleLength is the length generated session titles are cut to.
+const maxTitleLength = 60
+
+// ListChatSessions pages through the user's sessions, most recently
+// active first.
+func (h *Handler) ListChatSessions(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+
+	limit, cursor, ok := pageParams(c, 20)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+
+	sessions, err := h.store.ChatSessions.List(ctx, repository.ChatSessionFilter{
+		UserID: userID,
+		Limit:  limit + 1,
+		After:  cursor,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch chat sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newPage(sessions, limit, chatSessionCursor))
+}
+
+func (h *Handler) GetChatSession(c *gin.Context) {
+	session, ok := h.ownedSession(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// CreateChatSession starts an empty session. Without a title, one is
+// generated from the first message.
+func (h *Handler) CreateChatSession(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+
+	var req models.ChatSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	session := models.ChatSession{
+		ID:        utils.GenerateID(),
+		UserID:    userID,
+		Title:     strings.TrimSpace(req.Title),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := h.store.ChatSessions.Create(context.Background(), &session); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create chat session"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, session)
+}
+
+func (h *Handler) RenameChatSession(c *gin.Context) {
+	var req models.ChatSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Title is required"})
+		return
+	}
+
+	session, ok := h.ownedSession(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	if err := h.store.ChatSessions.UpdateTitle(context.Background(), session.ID, title); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not rename chat session"})
+		return
+	}
+
+	session.Title = title
+	c.JSON(http.StatusOK, session)
+}
+
+// DeleteChatSession removes a session together with its messages.
+func (h *Handler) DeleteChatSession(c *gin.Context) {
+	session, ok := h.ownedSession(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+
+	if err := h.store.ChatFeedback.DeleteBySession(ctx, session.UserID, session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not delete chat feedback"})
+		return
+	}
+	if err := h.store.ChatMessages.DeleteBySession(ctx, session.UserID, session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not delete chat messages"})
+		return
+	}
+	if err := h.store.ChatSessions.Delete(ctx, session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not delete chat session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Chat session deleted successfully"})
+}
+
+// ownedSession loads a session and checks it belongs to the caller,
+// writing the error response itself when it does not.
+func (h *Handler) ownedSession(c *gin.Context, sessionID string) (*models.ChatSession, bool) {
+	userID := c.MustGet("userId").(string)
+
+	session, err := h.store.ChatSessions.Get(context.Background(), sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Chat session not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch chat session"})
+		return nil, false
+	}
+
+	if session.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return nil, false
+	}
+
+	return session, true
+}
+
+// sessionForMessage returns the session a new message belongs to. An empty
+// ID starts a new session; an unknown one starts it under that ID so
+// clients that generate their own session IDs keep working. A new session
+// is not stored yet, so that a message the advisor fails to answer leaves
+// nothing behind; isNew tells the caller to create it with the exchange.
+func (h *Handler) sessionForMessage(c *gin.Context, sessionID string) (session *models.ChatSession, isNew, ok bool) {
+	userID := c.MustGet("userId").(string)
+
+	if sessionID != "" {
+		session, err := h.store.ChatSessions.Get(context.Background(), sessionID)
+		if err == nil {
+			if session.UserID != userID {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+				return nil, false, false
+			}
+			return session, false, true
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch chat session"})
+			return nil, false, false
+		}
+	} else {
+		sessionID = utils.GenerateID()
+	}
+
+	now := time.Now()
+	return &models.ChatSession{
+		ID:        sessionID,
+		UserID:    userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, true, true
+}
+
+// sessionHistory assembles the conversation so far from the session's
+// stored messages, within the RAG client's history budget.
+func (h *Handler) sessionHistory(ctx context.Context, session *models.ChatSession) ([]models.ChatHistoryItem, error) {
+	budget := h.RAG.HistoryBudget()
+	if session.MessageCount == 0 || budget.MaxMessages() == 0 {
+		return nil, nil
+	}
+
+	messages, err := h.store.ChatMessages.List(ctx, repository.ChatMessageFilter{
+		UserID:    session.UserID,
+		SessionID: session.ID,
+		Limit:     budget.MaxMessages(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return budget.Apply(reverseMessages(messages)), nil
+}
+
+// recordExchange updates a session after a user message and its answer were
+// stored, titling untitled sessions after their first message.
+func (h *Handler) recordExchange(ctx context.Context, session *models.ChatSession, firstMessage string, at time.Time) error {
+	if err := h.store.ChatSessions.AddMessages(ctx, session.ID, 2, at); err != nil {
+		return err
+	}
+	session.MessageCount += 2
+	session.UpdatedAt = at
+
+	if session.Title == "" {
+		session.Title = generateSessionTitle(firstMessage)
+		if err := h.store.ChatSessions.UpdateTitle(ctx, session.ID, session.Title); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generateSessionTitle derives a title from the opening message: the first
+// line, whitespace collapsed, cut at a word boundary.
+func generateSessionTitle(message string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	title := strings.Join(strings.Fields(line), " ")
+
+	if title == "" {
+		return "New conversation"
+	}
+	if utf8.RuneCountInString(title) <= maxTitleLength {
+		return title
+	}
+
+	runes := []rune(title)[:maxTitleLength]
+	if cut := strings.LastIndex(string(runes), " "); cut > maxTitleLength/2 {
+		return string(runes)[:cut] + "…"
+	}
+	return string(runes) + "…"
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"orchestrator-service/models"
+	"orchestrator-service/rag"
+	"orchestrator-service/redact"
+	"orchestrator-service/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StreamMessage is the streaming variant of SendMessage. It answers with
+// Server-Sent Events:
+//
+//	event: userMessage  the stored user message
+//	event: token        {"text": "..."} for each chunk of the answer
+//	event: done         {"aiMessage": ..., "session": ...} once stored
+//	event: error        {"error": "..."} if generation failed
+//
+// Both messages are only stored once the answer is complete. If the client
+// goes away the upstream request is cancelled and nothing is stored.
+func (h *Handler) StreamMessage(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+
+	var req models.ChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Cancelled when the client disconnects
+	ctx := c.Request.Context()
+
+	session, isNew, ok := h.sessionForMessage(c, req.SessionID)
+	if !ok {
+		return
+	}
+
+	triaged := h.triage(session, req.Message)
+
+	var (
+		ragReq   rag.Request
+		redactor *redact.Redactor
+	)
+	if triaged == nil {
+		var err error
+		ragReq, redactor, err = h.ragRequest(ctx, session, req.Message)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not prepare the conversation"})
+			return
+		}
+	}
+
+	userMessage := models.ChatMessage{
+		ID:        utils.GenerateID(),
+		UserID:    userID,
+		Text:      req.Message,
+		Sender:    "user",
+		Timestamp: time.Now(),
+		SessionID: session.ID,
+	}
+	flagMessages(triaged, &userMessage)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+	c.Status(http.StatusOK)
+
+	c.SSEvent("userMessage", userMessage)
+	c.Writer.Flush()
+
+	var answer string
+	if triaged != nil {
+		// Urgent-care guidance in one piece instead of the advisor's answer
+		answer = triaged.Response
+		c.SSEvent("token", gin.H{"text": answer})
+		c.Writer.Flush()
+	} else {
+		var ok bool
+		if answer, ok = h.streamAnswer(c, ragReq, redactor); !ok {
+			return
+		}
+	}
+
+	aiMessage := models.ChatMessage{
+		ID:        utils.GenerateID(),
+		UserID:    userID,
+		Text:      answer,
+		Sender:    "ai",
+		Timestamp: time.Now(),
+		SessionID: session.ID,
+	}
+	flagMessages(triaged, &aiMessage)
+
+	// The client is still connected here, but don't let a late disconnect
+	// lose the finished answer
+	saveCtx := context.Background()
+	if isNew {
+		if err := h.store.ChatSessions.Create(saveCtx, session); err != nil {
+			c.SSEvent("error", gin.H{"error": "Could not create chat session"})
+			c.Writer.Flush()
+			return
+		}
+	}
+	if err := h.store.ChatMessages.Create(saveCtx, &userMessage); err != nil {
+		c.SSEvent("error", gin.H{"error": "Could not save message"})
+		c.Writer.Flush()
+		return
+	}
+	if err := h.store.ChatMessages.Create(saveCtx, &aiMessage); err != nil {
+		c.SSEvent("error", gin.H{"error": "Could not save AI response"})
+		c.Writer.Flush()
+		return
+	}
+	if err := h.recordExchange(saveCtx, session, req.Message, aiMessage.Timestamp); err != nil {
+		c.SSEvent("error", gin.H{"error": "Could not update chat session"})
+		c.Writer.Flush()
+		return
+	}
+
+	c.SSEvent("done", gin.H{"aiMessage": aiMessage, "session": session})
+	c.Writer.Flush()
+}
+
+// streamAnswer relays the advisor's answer as token events and returns it
+// in full, or reports false once the stream has failed or the client has
+// gone.
+func (h *Handler) streamAnswer(c *gin.Context, req rag.Request, redactor *redact.Redactor) (string, bool) {
+	ctx := c.Request.Context()
+	userID := c.MustGet("userId").(string)
+
+	restorer := redactor.NewStreamRestorer()
+	answer, err := h.RAG.Stream(ctx, req, func(token string) {
+		if text := restorer.Write(token); text != "" {
+			c.SSEvent("token", gin.H{"text": text})
+			c.Writer.Flush()
+		}
+	})
+
+	if ctx.Err() != nil {
+		log.Printf("Chat stream for user %s cancelled by client", userID)
+		return "", false
+	}
+	if err != nil {
+		log.Printf("Chat stream failed: %v", err)
+		c.SSEvent("error", gin.H{"error": "Could not generate a response"})
+		c.Writer.Flush()
+		return "", false
+	}
+	if text := restorer.Flush(); text != "" {
+		c.SSEvent("token", gin.H{"text": text})
+		c.Writer.Flush()
+	}
+
+	return redactor.Restore(answer), true
+}
@@ -0,0 +1,445 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"orchestrator-service/catalog"
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+	"orchestrator-service/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListGoals returns the user's goals, each with its progress in the
+// current period when it applies today; other goals come without a period.
+// With active=true only the goals that apply today are listed.
+func (h *Handler) ListGoals(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+
+	goals, err := h.userGoals(ctx, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch goals"})
+		return
+	}
+
+	loc := user.Location()
+	today := localDate(time.Now(), loc)
+
+	// A week from Monday covers the current period of every goal
+	weekStart, weekEnd := goalPeriod("weekly", today)
+	from, _ := time.ParseInLocation("2006-01-02", weekStart, loc)
+	to, _ := time.ParseInLocation("2006-01-02", weekEnd, loc)
+	activities, err := h.store.Activities.List(ctx, repository.ActivityFilter{
+		UserID: user.ID,
+		From:   from,
+		To:     to.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch activities"})
+		return
+	}
+
+	activeOnly := c.Query("active") == "true"
+	progress := []models.GoalProgress{}
+	for i := range goals {
+		goal := &goals[i]
+		if !goal.ActiveOn(today) {
+			if !activeOnly {
+				progress = append(progress, models.GoalProgress{Goal: *goal})
+			}
+			continue
+		}
+		progress = append(progress, goalProgress(goal, activities, today, loc))
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// GetGoal returns a goal with its progress in the period containing date
+// (YYYY-MM-DD, today by default).
+func (h *Handler) GetGoal(c *gin.Context) {
+	goal, ok := h.ownedGoal(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	loc := user.Location()
+
+	day, err := parseDateParamIn(c.Query("date"), startOfDay(time.Now(), loc), loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date parameter, expected YYYY-MM-DD"})
+		return
+	}
+	date := localDate(day, loc)
+
+	start, end := goalPeriod(goal.Period, date)
+	from, _ := time.ParseInLocation("2006-01-02", start, loc)
+	to, _ := time.ParseInLocation("2006-01-02", end, loc)
+	activities, err := h.store.Activities.List(context.Background(), repository.ActivityFilter{
+		UserID: goal.UserID,
+		Type:   goal.Type,
+		From:   from,
+		To:     to.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch activities"})
+		return
+	}
+
+	c.JSON(http.StatusOK, goalProgress(goal, activities, date, loc))
+}
+
+func (h *Handler) CreateGoal(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+
+	var req models.GoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	goal := models.Goal{
+		ID:        utils.GenerateID(),
+		UserID:    userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if errs := applyGoalRequest(&goal, req, localDate(now, user.Location())); errs != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal", "fields": errs})
+		return
+	}
+
+	ctx := context.Background()
+
+	if !h.checkGoalOverlap(c, user, &goal) {
+		return
+	}
+
+	if err := h.store.Goals.Create(ctx, &goal); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create goal"})
+		return
+	}
+	h.recordGoalChange(ctx, &goal, "created", now)
+	h.checkAchievements(ctx, userID)
+
+	c.JSON(http.StatusCreated, goal)
+}
+
+// UpdateGoal replaces a goal's settings. The change is kept in the goal's
+// history.
+func (h *Handler) UpdateGoal(c *gin.Context) {
+	var req models.GoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, ok := h.ownedGoal(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	// An update without a start date keeps the current one
+	if req.StartDate == "" {
+		req.StartDate = goal.StartDate
+	}
+
+	now := time.Now()
+	if errs := applyGoalRequest(goal, req, localDate(now, user.Location())); errs != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal", "fields": errs})
+		return
+	}
+	goal.UpdatedAt = now
+
+	ctx := context.Background()
+
+	if !h.checkGoalOverlap(c, user, goal) {
+		return
+	}
+
+	if err := h.store.Goals.Update(ctx, goal); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not update goal"})
+		return
+	}
+	h.recordGoalChange(ctx, goal, "updated", now)
+	h.checkAchievements(ctx, goal.UserID)
+
+	c.JSON(http.StatusOK, goal)
+}
+
+func (h *Handler) DeleteGoal(c *gin.Context) {
+	goal, ok := h.ownedGoal(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+
+	if err := h.store.Goals.Delete(ctx, goal.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not delete goal"})
+		return
+	}
+	h.recordGoalChange(ctx, goal, "deleted", time.Now())
+	h.checkAchievements(ctx, goal.UserID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Goal deleted successfully"})
+}
+
+// GetGoalHistory lists the changes made to a goal, oldest first. The
+// history of a deleted goal stays available.
+func (h *Handler) GetGoalHistory(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+
+	changes, err := h.store.Goals.Changes(context.Background(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch goal history"})
+		return
+	}
+	if len(changes) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		return
+	}
+
+	// Check ownership
+	if changes[0].UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+// currentUser fetches the signed-in user, answering the request itself
+// when that fails.
+func (h *Handler) currentUser(c *gin.Context) (*models.User, bool) {
+	user, err := h.store.Users.Get(context.Background(), c.MustGet("userId").(string))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch user"})
+		return nil, false
+	}
+	return user, true
+}
+
+// ownedGoal fetches a goal, answering 404 or 403 itself when it does not
+// exist or belongs to someone else.
+func (h *Handler) ownedGoal(c *gin.Context, id string) (*models.Goal, bool) {
+	userID := c.MustGet("userId").(string)
+
+	goal, err := h.store.Goals.Get(context.Background(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch goal"})
+		return nil, false
+	}
+
+	// Verify the goal belongs to the user
+	if goal.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return nil, false
+	}
+
+	return goal, true
+}
+
+// checkGoalOverlap answers 409 itself when another of the user's goals
+// tracks the same thing over the same period on any of the goal's days.
+func (h *Handler) checkGoalOverlap(c *gin.Context, user *models.User, goal *models.Goal) bool {
+	goals, err := h.userGoals(context.Background(), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch goals"})
+		return false
+	}
+
+	for i := range goals {
+		other := &goals[i]
+		if other.ID == goal.ID || other.Type != goal.Type || other.Period != goal.Period || other.Unit != goal.Unit {
+			continue
+		}
+		if goal.Overlaps(other) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":  "Another " + goal.Period + " " + goal.Type + " goal covers some of these dates",
+				"goalId": other.ID,
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// userGoals lists the user's goals.
+func (h *Handler) userGoals(ctx context.Context, user *models.User) ([]models.Goal, error) {
+	return h.store.Goals.List(ctx, user.ID)
+}
+
+// recordGoalChange adds to the goal's history. A failure is only logged:
+// the change itself has already been made.
+func (h *Handler) recordGoalChange(ctx context.Context, goal *models.Goal, action string, at time.Time) {
+	change := models.GoalChange{
+		ID:        utils.GenerateID(),
+		GoalID:    goal.ID,
+		UserID:    goal.UserID,
+		Action:    action,
+		Type:      goal.Type,
+		Period:    goal.Period,
+		Target:    goal.Target,
+		Unit:      goal.Unit,
+		StartDate: goal.StartDate,
+		EndDate:   goal.EndDate,
+		ChangedAt: at,
+	}
+	if err := h.store.Goals.AddChange(ctx, &change); err != nil {
+		log.Printf("Could not record %s change of goal %s: %v", action, goal.ID, err)
+	}
+}
+
+// applyGoalRequest validates req and copies it onto goal. today fills in a
+// missing start date.
+func applyGoalRequest(goal *models.Goal, req models.GoalRequest, today string) []catalog.FieldError {
+	var errs []catalog.FieldError
+	fail := func(field, message string) {
+		errs = append(errs, catalog.FieldError{Field: field, Message: message})
+	}
+
+	t, ok := catalog.Lookup(req.Type)
+	switch {
+	case !ok:
+		fail("type", "is not a known activity type")
+	case !t.Summable:
+		fail("type", "cannot have a goal, as its values do not add up")
+	}
+
+	unit := req.Unit
+	if ok {
+		if unit == "" {
+			unit = t.Units[0].Name
+		}
+		if _, known := t.Unit(unit); !known {
+			fail("unit", "is not a unit of "+t.Name)
+		}
+		unit = t.Canonical(unit)
+	}
+
+	if req.Target <= 0 || math.IsInf(req.Target, 0) {
+		fail("target", "must be a positive number")
+	}
+
+	start := req.StartDate
+	if start == "" {
+		start = today
+	}
+	_, startErr := time.Parse("2006-01-02", start)
+	if startErr != nil {
+		fail("startDate", "must be a date as YYYY-MM-DD")
+	}
+	if req.EndDate != "" {
+		if _, err := time.Parse("2006-01-02", req.EndDate); err != nil {
+			fail("endDate", "must be a date as YYYY-MM-DD")
+		} else if startErr == nil && req.EndDate < start {
+			fail("endDate", "must not be before startDate")
+		}
+	}
+
+	if errs != nil {
+		return errs
+	}
+
+	goal.Type = req.Type
+	goal.Period = req.Period
+	goal.Target = req.Target
+	goal.Unit = unit
+	goal.StartDate = start
+	goal.EndDate = req.EndDate
+	return nil
+}
+
+// goalPeriod returns the first and last day of the period containing
+// date: the day itself, or its week from Monday to Sunday.
+func goalPeriod(period, date string) (string, string) {
+	if period != "weekly" {
+		return date, date
+	}
+
+	day, _ := time.Parse("2006-01-02", date)
+	offset := (int(day.Weekday()) + 6) % 7 // Days since Monday
+	monday := day.AddDate(0, 0, -offset)
+	return monday.Format("2006-01-02"), monday.AddDate(0, 0, 6).Format("2006-01-02")
+}
+
+// goalProgress adds up the activities counting towards the goal in the
+// period containing date. Activities outside that period are ignored, so
+// callers may pass a wider selection.
+func goalProgress(goal *models.Goal, activities []models.Activity, date string, loc *time.Location) models.GoalProgress {
+	start, end := goalPeriod(goal.Period, date)
+	t, _ := catalog.Lookup(goal.Type)
+
+	progress := models.GoalProgress{Goal: *goal, PeriodStart: start, PeriodEnd: end}
+	for _, activity := range activities {
+		if activity.Type != goal.Type || t.Canonical(activity.Unit) != goal.Unit {
+			continue
+		}
+		day := localDate(activity.Date, loc)
+		if day < start || day > end || !goal.ActiveOn(day) {
+			continue
+		}
+		progress.Value += activity.Value
+	}
+
+	progress.Value = math.Round(progress.Value*100) / 100
+	progress.Percent = math.Round(progress.Value/goal.Target*1000) / 10
+	progress.Achieved = progress.Value >= goal.Target
+	return progress
+}
+
+// dailyTargets picks the summary's targets from the daily goals that apply
+// on date, falling back to the defaults for the others.
+func dailyTargets(goals []models.Goal, date string) models.ActivityGoals {
+	var targets models.ActivityGoals
+	for _, goal := range goals {
+		if goal.Period != "daily" || !goal.ActiveOn(date) {
+			continue
+		}
+
+		switch {
+		case goal.Type == "steps" && goal.Unit == "steps":
+			targets.Steps = int(math.Round(goal.Target))
+		case goal.Type == "water" && goal.Unit == "glasses":
+			targets.Water = int(math.Round(goal.Target))
+		case goal.Type == "sleep" && goal.Unit == "hours":
+			targets.Sleep = goal.Target
+		}
+	}
+	return targets.WithDefaults()
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+
+	"orchestrator-service/mail"
+	"orchestrator-service/rag"
+	"orchestrator-service/ratelimit"
+	"orchestrator-service/repository"
+	"orchestrator-service/triage"
+
+	"firebase.google.com/go/auth"
+)
+
+// GoogleVerifier resolves a Firebase ID token to the Google account that
+// signed in.
+type GoogleVerifier interface {
+	VerifyGoogleToken(ctx context.Context, idToken string) (*auth.UserRecord, error)
+}
+
+// Handler serves the HTTP API on top of a storage backend.
+type Handler struct {
+	store *repository.Store
+
+	// Google verifies Google sign-in tokens. When nil, Google sign-in is
+	// disabled.
+	Google GoogleVerifier
+
+	// RAG answers chat messages.
+	RAG *rag.Client
+
+	// Triage screens chat messages for emergencies before they reach RAG.
+	Triage *triage.Classifier
+
+	// Mailer delivers password reset and verification emails, and heart
+	// rate alerts to users who opted in.
+	Mailer mail.Sender
+
+	// Attempts counts wrong second factors per login challenge.
+	Attempts ratelimit.Store
+
+	// AppURL is the frontend's base URL and APIURL this service's public
+	// base URL; both are used to build links in emails.
+	AppURL string
+	APIURL string
+
+	// rollupLocks keeps rebuilds and refreshes of one user's rollups from
+	// interleaving.
+	rollupLocks userLocks
+}
+
+func New(store *repository.Store) *Handler {
+	return &Handler{
+		store:    store,
+		RAG:      rag.NewClient(rag.DefaultConfig()),
+		Triage:   triage.Default(),
+		Mailer:   mail.LogSender{},
+		Attempts: ratelimit.NewMemoryStore(),
+		AppURL:   "http://localhost:8002",
+		APIURL:   "http://localhost:8001",
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+)
+
+// healthContext builds the personal context sent with a chat query. It
+// returns nil unless the user enabled personalized advice. Only what is
+// useful for health advice is included: no name, email or identifiers.
+func (h *Handler) healthContext(ctx context.Context, user *models.User) (*models.HealthContext, error) {
+	if !user.Settings.PersonalizedAdvice {
+		return nil, nil
+	}
+
+	healthContext := &models.HealthContext{
+		Gender:      user.Gender,
+		Height:      user.Height,
+		Weight:      user.Weight,
+		Allergies:   strings.TrimSpace(user.Allergies),
+		Medications: strings.TrimSpace(user.Medications),
+		Conditions:  strings.TrimSpace(user.Conditions),
+	}
+	healthContext.Age = user.Age(time.Now())
+
+	// The past seven days, today included
+	loc := user.Location()
+	today := startOfDay(time.Now(), loc)
+	activities, err := h.store.Activities.List(ctx, repository.ActivityFilter{
+		UserID: user.ID,
+		From:   today.AddDate(0, 0, -6),
+		To:     today.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if recent := summarizeRecentActivity(activities, loc); *recent != (models.RecentActivity{}) {
+		healthContext.RecentActivity = recent
+	}
+
+	return healthContext, nil
+}
+
+// summarizeRecentActivity averages steps and sleep over the days that have
+// entries, so a few unlogged days don't drag the averages down. Days are
+// those of loc.
+func summarizeRecentActivity(activities []models.Activity, loc *time.Location) *models.RecentActivity {
+	var (
+		stepsByDay     = make(map[string]float64)
+		sleepByDay     = make(map[string]float64)
+		activeMinutes  float64
+		heartRateSum   float64
+		heartRateCount int
+	)
+
+	for _, activity := range activities {
+		day := localDate(activity.Date, loc)
+
+		switch activity.Type {
+		case "steps":
+			stepsByDay[day] += activity.Value
+		case "sleep":
+			sleepByDay[day] += activity.Value
+		case "heart_rate":
+			heartRateSum += activity.Value
+			heartRateCount++
+		case "exercise":
+			if activity.Unit == "min" || activity.Unit == "minutes" {
+				activeMinutes += activity.Value
+			}
+		}
+	}
+
+	recent := &models.RecentActivity{
+		AvgDailySteps: int(math.Round(average(stepsByDay))),
+		ActiveMinutes: int(math.Round(activeMinutes)),
+		AvgSleep:      math.Round(average(sleepByDay)*10) / 10,
+	}
+	if heartRateCount > 0 {
+		recent.AvgHeartRate = int(math.Round(heartRateSum / float64(heartRateCount)))
+	}
+
+	return recent
+}
+
+func average(byDay map[string]float64) float64 {
+	if len(byDay) == 0 {
+		return 0
+	}
+
+	var total float64
+	for _, value := range byDay {
+		total += value
+	}
+	return total / float64(len(byDay))
+}
+
+// auditHealthContext logs which fields of the user's data are about to
+// leave the service. Values are not logged.
+func auditHealthContext(userID string, healthContext *models.HealthContext) {
+	if healthContext == nil {
+		return
+	}
+
+	log.Printf("AUDIT: health context sent to RAG for user %s: fields=[%s]",
+		userID, strings.Join(contextFields(healthContext), ","))
+}
+
+// contextFields lists the JSON fields present in the serialized context,
+// which is exactly what goes over the wire.
+func contextFields(healthContext *models.HealthContext) []string {
+	data, err := json.Marshal(healthContext)
+	if err != nil {
+		return nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+
+	var names []string
+	for name, value := range fields {
+		if nested, ok := value.(map[string]any); ok {
+			for child := range nested {
+				names = append(names, name+"."+child)
+			}
+			continue
+		}
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
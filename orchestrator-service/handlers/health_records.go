@@ -2,46 +2,42 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
-	"orchestrator-service/database"
 	"orchestrator-service/models"
+	"orchestrator-service/repository"
 	"orchestrator-service/utils"
 
 	"github.com/gin-gonic/gin"
-	"google.golang.org/api/iterator"
 )
 
-func GetHealthRecords(c *gin.Context) {
+// GetHealthRecords pages through the user's records in the order they
+// were created.
+func (h *Handler) GetHealthRecords(c *gin.Context) {
 	userID := c.MustGet("userId").(string)
 
-	ctx := context.Background()
-	iter := database.Client.Collection("health_records").Where("userId", "==", userID).Documents(ctx)
-
-	var records []models.HealthRecord
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch health records"})
-			return
-		}
+	limit, cursor, ok := pageParams(c, 50)
+	if !ok {
+		return
+	}
 
-		var record models.HealthRecord
-		if err := doc.DataTo(&record); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not decode health records"})
-			return
-		}
-		records = append(records, record)
+	ctx := context.Background()
+	records, err := h.store.HealthRecords.List(ctx, repository.HealthRecordFilter{
+		UserID: userID,
+		Limit:  limit + 1,
+		After:  cursor,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch health records"})
+		return
 	}
 
-	c.JSON(http.StatusOK, records)
+	c.JSON(http.StatusOK, newPage(records, limit, healthRecordCursor))
 }
 
-func CreateHealthRecord(c *gin.Context) {
+func (h *Handler) CreateHealthRecord(c *gin.Context) {
 	userID := c.MustGet("userId").(string)
 
 	var record models.HealthRecord
@@ -56,8 +52,7 @@ func CreateHealthRecord(c *gin.Context) {
 
 	ctx := context.Background()
 
-	_, err := database.Client.Collection("health_records").Doc(record.ID).Set(ctx, record)
-	if err != nil {
+	if err := h.store.HealthRecords.Create(ctx, &record); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create health record"})
 		return
 	}
@@ -65,22 +60,20 @@ func CreateHealthRecord(c *gin.Context) {
 	c.JSON(http.StatusCreated, record)
 }
 
-func DeleteHealthRecord(c *gin.Context) {
+func (h *Handler) DeleteHealthRecord(c *gin.Context) {
 	userID := c.MustGet("userId").(string)
 	recordID := c.Param("id")
 
 	ctx := context.Background()
 
 	// Verify the record belongs to the user
-	doc, err := database.Client.Collection("health_records").Doc(recordID).Get(ctx)
+	record, err := h.store.HealthRecords.Get(ctx, recordID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Health record not found"})
-		return
-	}
-
-	var record models.HealthRecord
-	if err := doc.DataTo(&record); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not decode health record"})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Health record not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch health record"})
 		return
 	}
 
@@ -89,8 +82,7 @@ func DeleteHealthRecord(c *gin.Context) {
 		return
 	}
 
-	_, err = database.Client.Collection("health_records").Doc(recordID).Delete(ctx)
-	if err != nil {
+	if err := h.store.HealthRecords.Delete(ctx, recordID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not delete health record"})
 		return
 	}
@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"orchestrator-service/heartrate"
+	"orchestrator-service/mail"
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+	"orchestrator-service/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// baselineWindow is how far back readings count towards the baseline.
+const baselineWindow = 30 * 24 * time.Hour
+
+// detectionWindow is how far either side of a written reading runs of
+// readings are looked for.
+const detectionWindow = 24 * time.Hour
+
+// GetHeartRateBaseline returns the user's resting heart rate, or null until
+// there are enough days of readings, with the thresholds readings are
+// judged against.
+func (h *Handler) GetHeartRateBaseline(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	baseline, thresholds, err := h.heartRateThresholds(context.Background(), user, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not compute heart rate baseline"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"baseline":   baseline,
+		"thresholds": thresholds,
+		"minDays":    heartrate.MinBaselineDays,
+	})
+}
+
+// ListHeartRateAlerts lists the user's alerts, latest first, optionally
+// only those with status open, acknowledged or withdrawn.
+func (h *Handler) ListHeartRateAlerts(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+
+	status := c.Query("status")
+	if status != "" && status != models.AlertOpen && status != models.AlertAcknowledged && status != models.AlertWithdrawn {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be open, acknowledged or withdrawn"})
+		return
+	}
+
+	limit, cursor, ok := pageParams(c, 20)
+	if !ok {
+		return
+	}
+
+	alerts, err := h.store.HeartRateAlerts.List(context.Background(), repository.HeartRateAlertFilter{
+		UserID: userID,
+		Status: status,
+		Limit:  limit + 1,
+		After:  cursor,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch alerts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newPage(alerts, limit, heartRateAlertCursor))
+}
+
+// AcknowledgeHeartRateAlert marks an open alert as seen. Acknowledging it
+// again keeps the original time; withdrawn alerts stay withdrawn.
+func (h *Handler) AcknowledgeHeartRateAlert(c *gin.Context) {
+	alert, ok := h.ownedHeartRateAlert(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	if alert.Status == models.AlertOpen {
+		now := time.Now()
+		alert.Status = models.AlertAcknowledged
+		alert.AcknowledgedAt = &now
+		alert.UpdatedAt = now
+
+		if err := h.store.HeartRateAlerts.Update(context.Background(), alert); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not acknowledge alert"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, alert)
+}
+
+// ownedHeartRateAlert fetches an alert, answering 404 or 403 itself when
+// it does not exist or belongs to someone else.
+func (h *Handler) ownedHeartRateAlert(c *gin.Context, id string) (*models.HeartRateAlert, bool) {
+	userID := c.MustGet("userId").(string)
+
+	alert, err := h.store.HeartRateAlerts.Get(context.Background(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Alert not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch alert"})
+		return nil, false
+	}
+
+	// Verify the alert belongs to the user
+	if alert.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return nil, false
+	}
+
+	return alert, true
+}
+
+// heartRateThresholds works out the user's baseline from the readings of
+// the past baselineWindow and the thresholds that follow from it.
+func (h *Handler) heartRateThresholds(ctx context.Context, user *models.User, now time.Time) (*heartrate.Baseline, heartrate.Thresholds, error) {
+	activities, err := h.store.Activities.List(ctx, repository.ActivityFilter{
+		UserID: user.ID,
+		From:   now.Add(-baselineWindow),
+	})
+	if err != nil {
+		return nil, heartrate.Thresholds{}, err
+	}
+
+	var readings []models.Activity
+	for _, activity := range activities {
+		if activity.Type == "heart_rate" {
+			readings = append(readings, activity)
+		}
+	}
+
+	age := user.Age(now)
+	baseline := heartrate.NewBaseline(readings, heartrate.ExerciseWindows(activities), user.Location())
+	return baseline, heartrate.ThresholdsFor(age, baseline), nil
+}
+
+// checkHeartRate looks for sustained runs of out-of-range readings around
+// the given times, after heart rate readings on them were written or
+// deleted. It runs after the activity is stored, so failures are only
+// logged.
+func (h *Handler) checkHeartRate(ctx context.Context, userID string, times ...time.Time) {
+	user, err := h.store.Users.Get(ctx, userID)
+	if err == nil {
+		for _, t := range times {
+			if err = h.detectHeartRate(ctx, user, t); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		log.Printf("Could not check heart rate for user %s: %v", userID, err)
+	}
+}
+
+// detectHeartRate records an alert for each episode within detectionWindow
+// of at. An episode overlapping an existing alert of the same kind updates
+// that alert, which keeps its status: a run that goes on after the user
+// acknowledged it does not raise a second alert. Alerts within the window
+// that no episode matches any more are withdrawn, and brought back if a
+// later edit restores their run. The window reaches back to the start of
+// alerts running into it, so their runs are seen whole.
+func (h *Handler) detectHeartRate(ctx context.Context, user *models.User, at time.Time) error {
+	now := time.Now()
+
+	baseline, thresholds, err := h.heartRateThresholds(ctx, user, now)
+	if err != nil {
+		return err
+	}
+
+	existing, err := h.store.HeartRateAlerts.List(ctx, repository.HeartRateAlertFilter{
+		UserID: user.ID,
+		From:   at.Add(-2 * detectionWindow),
+	})
+	if err != nil {
+		return err
+	}
+
+	from, to := at.Add(-detectionWindow), at.Add(detectionWindow)
+	for _, alert := range existing {
+		if alert.Start.Before(from) && !alert.End.Before(from) {
+			from = alert.Start
+		}
+	}
+	activities, err := h.store.Activities.List(ctx, repository.ActivityFilter{
+		UserID: user.ID,
+		From:   from,
+		To:     to,
+	})
+	if err != nil {
+		return err
+	}
+	episodes := heartrate.Detect(activities, thresholds, heartrate.ExerciseWindows(activities))
+
+	var resting float64
+	if baseline != nil {
+		resting = baseline.Resting
+	}
+
+	matched := make(map[string]bool)
+	for _, episode := range episodes {
+		alert := matchingAlert(existing, episode)
+		created := alert == nil
+		if created {
+			alert = &models.HeartRateAlert{
+				ID:        utils.GenerateID(),
+				UserID:    user.ID,
+				Kind:      episode.Kind,
+				Status:    models.AlertOpen,
+				CreatedAt: now,
+			}
+		} else {
+			matched[alert.ID] = true
+			if alert.Status == models.AlertWithdrawn {
+				alert.Status = models.AlertOpen
+				if alert.AcknowledgedAt != nil {
+					alert.Status = models.AlertAcknowledged
+				}
+			} else if alert.Start.Equal(episode.Start) && alert.End.Equal(episode.End) &&
+				alert.Readings == episode.Readings && alert.Peak == episode.Peak {
+				continue
+			}
+		}
+
+		alert.Threshold = episode.Threshold
+		alert.Baseline = resting
+		alert.Peak = episode.Peak
+		alert.Readings = episode.Readings
+		alert.Start = episode.Start
+		alert.End = episode.End
+		alert.UpdatedAt = now
+
+		if !created {
+			if err := h.store.HeartRateAlerts.Update(ctx, alert); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := h.store.HeartRateAlerts.Create(ctx, alert); err != nil {
+			return err
+		}
+		log.Printf("Heart rate alert %s (%s) for user %s", alert.ID, alert.Kind, user.ID)
+		go h.notifyHeartRateAlert(user, alert.ID)
+	}
+
+	for i := range existing {
+		alert := &existing[i]
+		if matched[alert.ID] || alert.Status == models.AlertWithdrawn || alert.Start.Before(from) || alert.End.After(to) {
+			continue
+		}
+		alert.Status = models.AlertWithdrawn
+		alert.UpdatedAt = now
+		if err := h.store.HeartRateAlerts.Update(ctx, alert); err != nil {
+			return err
+		}
+		log.Printf("Heart rate alert %s for user %s withdrawn", alert.ID, user.ID)
+	}
+	return nil
+}
+
+// matchingAlert finds the alert of the episode's kind whose run overlaps
+// it.
+func matchingAlert(alerts []models.HeartRateAlert, episode heartrate.Episode) *models.HeartRateAlert {
+	for i := range alerts {
+		alert := &alerts[i]
+		if alert.Kind == episode.Kind && !alert.Start.After(episode.End) && !episode.Start.After(alert.End) {
+			return alert
+		}
+	}
+	return nil
+}
+
+// notifyHeartRateAlert emails the user about a new alert when they have
+// turned on both email notifications and health alerts (MedicationAlerts)
+// and their address is verified. It runs in the background so that a slow
+// mail server does not hold up the activity write that raised the alert.
+func (h *Handler) notifyHeartRateAlert(user *models.User, alertID string) {
+	if !user.Settings.EmailNotifications || !user.Settings.MedicationAlerts || !user.EmailVerified {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+	defer cancel()
+
+	alert, err := h.store.HeartRateAlerts.Get(ctx, alertID)
+	if err != nil {
+		log.Printf("Could not load heart rate alert %s to notify: %v", alertID, err)
+		return
+	}
+
+	direction := "above"
+	if alert.Kind == models.Bradycardia {
+		direction = "below"
+	}
+	loc := user.Location()
+	const layout = "Mon 2 Jan 15:04"
+
+	err = h.Mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Unusual heart rate readings",
+		Body: fmt.Sprintf("Hi %s,\n\nBetween %s and %s you logged %d heart rate readings %s %.0f bpm, reaching %.0f bpm.\n\n"+
+			"This can have harmless causes, a faulty reading among them, but if it keeps happening, talk to your doctor. "+
+			"If you have chest pain, shortness of breath, dizziness or fainting, seek medical help straight away.\n\n"+
+			"You can review and acknowledge this alert in Health Advisor.\n",
+			user.FullName, alert.Start.In(loc).Format(layout), alert.End.In(loc).Format(layout),
+			alert.Readings, direction, alert.Threshold, alert.Peak),
+	})
+	if err != nil {
+		log.Printf("Could not email heart rate alert %s to user %s: %v", alert.ID, user.ID, err)
+		return
+	}
+
+	// Reloaded, as detection may have grown the alert in the meantime
+	if alert, err = h.store.HeartRateAlerts.Get(context.Background(), alertID); err != nil {
+		log.Printf("Could not record notification of heart rate alert %s: %v", alertID, err)
+		return
+	}
+	now := time.Now()
+	alert.NotifiedAt = &now
+	if err := h.store.HeartRateAlerts.Update(context.Background(), alert); err != nil {
+		log.Printf("Could not record notification of heart rate alert %s: %v", alert.ID, err)
+	}
+}
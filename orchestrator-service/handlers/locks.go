@@ -0,0 +1,43 @@
+package handlers
+
+import "sync"
+
+// userLocks serializes work per user within this process, such as
+// rebuilding and refreshing one user's rollups.
+type userLocks struct {
+	mu    sync.Mutex
+	locks map[string]*userLock
+}
+
+type userLock struct {
+	sync.Mutex
+	users int // Holders and waiters; the lock is dropped at zero
+}
+
+// lock blocks until the user's lock is free and returns the function that
+// releases it.
+func (l *userLocks) lock(userID string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*userLock)
+	}
+	ul, ok := l.locks[userID]
+	if !ok {
+		ul = &userLock{}
+		l.locks[userID] = ul
+	}
+	ul.users++
+	l.mu.Unlock()
+
+	ul.Lock()
+	return func() {
+		ul.Unlock()
+
+		l.mu.Lock()
+		ul.users--
+		if ul.users == 0 {
+			delete(l.locks, userID)
+		}
+		l.mu.Unlock()
+	}
+}
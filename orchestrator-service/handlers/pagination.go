@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxPageSize = 100
+
+// cursorToken is what an opaque cursor decodes to.
+type cursorToken struct {
+	Time time.Time `json:"t"`
+	ID   string    `json:"id"`
+}
+
+func encodeCursor(cursor repository.Cursor) string {
+	data, _ := json.Marshal(cursorToken{Time: cursor.Time, ID: cursor.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*repository.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var token cursorToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, err
+	}
+	if token.ID == "" {
+		return nil, errors.New("cursor without id")
+	}
+	return &repository.Cursor{Time: token.Time, ID: token.ID}, nil
+}
+
+// pageParams reads the limit and cursor query parameters, answering 400
+// itself when they are invalid.
+func pageParams(c *gin.Context, defaultLimit int) (int, *repository.Cursor, bool) {
+	limit, err := parseInt(c.Query("limit"), defaultLimit)
+	if err != nil || limit < 1 || limit > maxPageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+		return 0, nil, false
+	}
+
+	var cursor *repository.Cursor
+	if s := c.Query("cursor"); s != "" {
+		if cursor, err = decodeCursor(s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor parameter"})
+			return 0, nil, false
+		}
+	}
+
+	return limit, cursor, true
+}
+
+// newPage builds the response from items fetched with a limit one higher
+// than requested; the extra item only tells that another page follows.
+func newPage[T any](items []T, limit int, key func(T) repository.Cursor) models.Page[T] {
+	page := models.Page[T]{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.HasMore = true
+		page.NextCursor = encodeCursor(key(page.Items[limit-1]))
+	}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	return page
+}
+
+func activityCursor(activity models.Activity) repository.Cursor {
+	return repository.Cursor{Time: activity.Date, ID: activity.ID}
+}
+
+func healthRecordCursor(record models.HealthRecord) repository.Cursor {
+	return repository.Cursor{Time: record.CreatedAt, ID: record.ID}
+}
+
+func chatMessageCursor(message models.ChatMessage) repository.Cursor {
+	return repository.Cursor{Time: message.Timestamp, ID: message.ID}
+}
+
+func chatSessionCursor(session models.ChatSession) repository.Cursor {
+	return repository.Cursor{Time: session.UpdatedAt, ID: session.ID}
+}
+
+func heartRateAlertCursor(alert models.HeartRateAlert) repository.Cursor {
+	return repository.Cursor{Time: alert.Start, ID: alert.ID}
+}
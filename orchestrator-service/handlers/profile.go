@@ -2,36 +2,34 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
-	"orchestrator-service/database"
 	"orchestrator-service/models"
+	"orchestrator-service/repository"
 
-	"cloud.google.com/go/firestore"
 	"github.com/gin-gonic/gin"
 )
 
-func GetProfile(c *gin.Context) {
+func (h *Handler) GetProfile(c *gin.Context) {
 	userID := c.MustGet("userId").(string)
 
 	ctx := context.Background()
-	doc, err := database.Client.Collection("users").Doc(userID).Get(ctx)
+	user, err := h.store.Users.Get(ctx, userID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-		return
-	}
-
-	var user models.User
-	if err := doc.DataTo(&user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not decode user"})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch user"})
 		return
 	}
 
 	c.JSON(http.StatusOK, user)
 }
 
-func UpdateProfile(c *gin.Context) {
+func (h *Handler) UpdateProfile(c *gin.Context) {
 	userID := c.MustGet("userId").(string)
 
 	var updateData struct {
@@ -44,6 +42,7 @@ func UpdateProfile(c *gin.Context) {
 		Allergies   string    `json:"allergies"`
 		Medications string    `json:"medications"`
 		Conditions  string    `json:"conditions"`
+		Timezone    string    `json:"timezone"` // Left unchanged when empty
 	}
 
 	if err := c.ShouldBindJSON(&updateData); err != nil {
@@ -51,30 +50,44 @@ func UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	if updateData.Timezone != "" {
+		if _, err := time.LoadLocation(updateData.Timezone); err != nil || updateData.Timezone == "Local" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown time zone, expected an IANA name such as Europe/Bucharest"})
+			return
+		}
+	}
 
-	_, err := database.Client.Collection("users").Doc(userID).Update(ctx, []firestore.Update{
-		{Path: "fullName", Value: updateData.FullName},
-		{Path: "dateOfBirth", Value: updateData.DateOfBirth},
-		{Path: "gender", Value: updateData.Gender},
-		{Path: "height", Value: updateData.Height},
-		{Path: "weight", Value: updateData.Weight},
-		{Path: "bloodType", Value: updateData.BloodType},
-		{Path: "allergies", Value: updateData.Allergies},
-		{Path: "medications", Value: updateData.Medications},
-		{Path: "conditions", Value: updateData.Conditions},
-		{Path: "updatedAt", Value: time.Now()},
-	})
+	ctx := context.Background()
 
+	user, err := h.store.Users.Get(ctx, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not update profile"})
 		return
 	}
 
+	user.FullName = updateData.FullName
+	user.DateOfBirth = updateData.DateOfBirth
+	user.Gender = updateData.Gender
+	user.Height = updateData.Height
+	user.Weight = updateData.Weight
+	user.BloodType = updateData.BloodType
+	user.Allergies = updateData.Allergies
+	user.Medications = updateData.Medications
+	user.Conditions = updateData.Conditions
+	if updateData.Timezone != "" {
+		user.Timezone = updateData.Timezone
+	}
+	user.UpdatedAt = time.Now()
+
+	if err := h.store.Users.Save(ctx, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not update profile"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Profile updated successfully"})
 }
 
-func UpdateSettings(c *gin.Context) {
+func (h *Handler) UpdateSettings(c *gin.Context) {
 	userID := c.MustGet("userId").(string)
 
 	var settings models.UserSettings
@@ -85,15 +98,22 @@ func UpdateSettings(c *gin.Context) {
 
 	ctx := context.Background()
 
-	_, err := database.Client.Collection("users").Doc(userID).Update(ctx, []firestore.Update{
-		{Path: "settings", Value: settings},
-		{Path: "updatedAt", Value: time.Now()},
-	})
-
+	user, err := h.store.Users.Get(ctx, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not update settings"})
 		return
 	}
 
+	// Two-factor authentication is switched through the /auth/2fa endpoints
+	settings.TwoFactorAuth = user.Settings.TwoFactorAuth
+
+	user.Settings = settings
+	user.UpdatedAt = time.Now()
+
+	if err := h.store.Users.Save(ctx, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not update settings"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Settings updated successfully"})
 }
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"log"
+
+	"orchestrator-service/models"
+	"orchestrator-service/rag"
+	"orchestrator-service/redact"
+)
+
+// ragRequest assembles what is sent to the RAG service for a new message:
+// the session's history, the opt-in health context, and everything run
+// through a redactor. The returned redactor restores the answer.
+func (h *Handler) ragRequest(ctx context.Context, session *models.ChatSession, message string) (rag.Request, *redact.Redactor, error) {
+	user, err := h.store.Users.Get(ctx, session.UserID)
+	if err != nil {
+		return rag.Request{}, nil, err
+	}
+
+	history, err := h.sessionHistory(ctx, session)
+	if err != nil {
+		return rag.Request{}, nil, err
+	}
+
+	healthContext, err := h.healthContext(ctx, user)
+	if err != nil {
+		return rag.Request{}, nil, err
+	}
+	auditHealthContext(user.ID, healthContext)
+
+	redactor := redact.New(append(redact.DefaultDetectors(), redact.Names(user.FullName))...)
+
+	req := rag.Request{
+		Query:   redactor.Redact(message),
+		History: make([]models.ChatHistoryItem, len(history)),
+		Context: healthContext,
+	}
+	for i, item := range history {
+		item.Text = redactor.Redact(item.Text)
+		req.History[i] = item
+	}
+	// Free-text profile fields can hold names, numbers and dates as well
+	if healthContext != nil {
+		healthContext.Allergies = redactor.Redact(healthContext.Allergies)
+		healthContext.Medications = redactor.Redact(healthContext.Medications)
+		healthContext.Conditions = redactor.Redact(healthContext.Conditions)
+	}
+
+	if n := redactor.Count(); n > 0 {
+		log.Printf("Redacted %d personal values from chat request for user %s", n, user.ID)
+	}
+
+	return req, redactor, nil
+}
@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"orchestrator-service/catalog"
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxTrendBuckets bounds how many buckets one trends request may ask for.
+const maxTrendBuckets = 400
+
+// GetActivityTrends aggregates the user's activities per day, week (from
+// Monday) or month between from and to (YYYY-MM-DD, in the user's time
+// zone), optionally for one type. The range is widened to whole weeks or
+// months; by default it covers the last 30 days, 12 weeks or 12 months.
+func (h *Handler) GetActivityTrends(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	loc := user.Location()
+	today := startOfDay(time.Now(), loc)
+
+	granularity := c.DefaultQuery("granularity", "day")
+	var defaultFrom time.Time
+	switch granularity {
+	case "day":
+		defaultFrom = today.AddDate(0, 0, -29)
+	case "week":
+		defaultFrom = today.AddDate(0, 0, -7*11)
+	case "month":
+		defaultFrom = today.AddDate(0, -11, 0)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "granularity must be day, week or month"})
+		return
+	}
+
+	from, err := parseDateParamIn(c.Query("from"), defaultFrom, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from parameter, expected YYYY-MM-DD"})
+		return
+	}
+	to, err := parseDateParamIn(c.Query("to"), today, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to parameter, expected YYYY-MM-DD"})
+		return
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	activityType := c.Query("type")
+	if activityType != "" {
+		if _, ok := catalog.Lookup(activityType); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown activity type"})
+			return
+		}
+	}
+
+	buckets, err := trendBuckets(from, to, granularity)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+
+	if err := h.ensureRollups(ctx, user); err != nil {
+		log.Printf("Could not build activity rollups for user %s: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not compute trends"})
+		return
+	}
+
+	rollups, err := h.store.Rollups.List(ctx, repository.ActivityRollupFilter{
+		UserID: user.ID,
+		Type:   activityType,
+		From:   buckets[0][0],
+		To:     buckets[len(buckets)-1][1],
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not compute trends"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"granularity": granularity,
+		"from":        buckets[0][0],
+		"to":          buckets[len(buckets)-1][1],
+		"timezone":    loc.String(),
+		"series":      trendSeries(rollups, buckets),
+	})
+}
+
+// trendBuckets splits the days from from to to into whole days, weeks or
+// months, as pairs of inclusive dates.
+func trendBuckets(from, to time.Time, granularity string) ([][2]string, error) {
+	var next func(time.Time) time.Time
+	switch granularity {
+	case "day":
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	case "week":
+		from = from.AddDate(0, 0, -((int(from.Weekday()) + 6) % 7)) // Back to Monday
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	case "month":
+		from = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location())
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	}
+
+	var buckets [][2]string
+	for start := from; !start.After(to); start = next(start) {
+		if len(buckets) == maxTrendBuckets {
+			return nil, errors.New("date range too large for this granularity")
+		}
+		end := next(start).AddDate(0, 0, -1)
+		buckets = append(buckets, [2]string{start.Format("2006-01-02"), end.Format("2006-01-02")})
+	}
+	return buckets, nil
+}
+
+// trendSeries combines daily rollups into buckets, one series per type and
+// unit, ordered as in the catalog.
+func trendSeries(rollups []models.ActivityRollup, buckets [][2]string) []models.TrendSeries {
+	type key struct{ activityType, unit string }
+	byKey := make(map[key]*models.TrendSeries)
+	var series []*models.TrendSeries
+
+	for _, rollup := range rollups {
+		k := key{rollup.Type, rollup.Unit}
+		s, ok := byKey[k]
+		if !ok {
+			s = &models.TrendSeries{Type: rollup.Type, Unit: rollup.Unit, Buckets: make([]models.TrendBucket, len(buckets))}
+			for i, b := range buckets {
+				s.Buckets[i] = models.TrendBucket{Start: b[0], End: b[1]}
+			}
+			byKey[k] = s
+			series = append(series, s)
+		}
+
+		// Buckets are in date order
+		i := sort.Search(len(buckets), func(i int) bool { return buckets[i][1] >= rollup.Date })
+		if i == len(buckets) || rollup.Date < buckets[i][0] {
+			continue
+		}
+
+		b := &s.Buckets[i]
+		if b.Count == 0 || rollup.Min < *b.Min {
+			low := rollup.Min
+			b.Min = &low
+		}
+		if b.Count == 0 || rollup.Max > *b.Max {
+			high := rollup.Max
+			b.Max = &high
+		}
+		b.Count += rollup.Count
+		b.Days++
+		b.Sum += rollup.Sum
+	}
+
+	order := make(map[string]int, len(catalog.Types))
+	for i, t := range catalog.Types {
+		order[t.Name] = i
+	}
+	sort.SliceStable(series, func(i, j int) bool {
+		if series[i].Type != series[j].Type {
+			return order[series[i].Type] < order[series[j].Type]
+		}
+		return series[i].Unit < series[j].Unit
+	})
+
+	result := make([]models.TrendSeries, len(series))
+	for i, s := range series {
+		for j := range s.Buckets {
+			b := &s.Buckets[j]
+			b.Sum = math.Round(b.Sum*100) / 100
+			if b.Count > 0 {
+				avg := math.Round(b.Sum/float64(b.Count)*100) / 100
+				b.Avg = &avg
+			}
+		}
+		result[i] = *s
+	}
+	return result
+}
+
+// ensureRollups rebuilds all of the user's rollups when they have never
+// been built, were built for another time zone or were marked out of date.
+func (h *Handler) ensureRollups(ctx context.Context, user *models.User) error {
+	unlock := h.rollupLocks.lock(user.ID)
+	defer unlock()
+
+	loc := user.Location()
+	zone, err := h.store.Rollups.Zone(ctx, user.ID)
+	if err != nil || zone == loc.String() {
+		return err
+	}
+
+	// Cleared first, so rollups left half built are rebuilt next time
+	if err := h.store.Rollups.SetZone(ctx, user.ID, ""); err != nil {
+		return err
+	}
+	if err := h.store.Rollups.DeleteByUser(ctx, user.ID); err != nil {
+		return err
+	}
+	activities, err := h.store.Activities.List(ctx, repository.ActivityFilter{UserID: user.ID})
+	if err != nil {
+		return err
+	}
+	for date, rollups := range rollupDays(activities, loc) {
+		if err := h.store.Rollups.ReplaceDay(ctx, user.ID, date, rollups); err != nil {
+			return err
+		}
+	}
+
+	return h.store.Rollups.SetZone(ctx, user.ID, loc.String())
+}
+
+// refreshRollups recomputes the rollups of the user's days containing the
+// given times, after activities on them were written. If that fails the
+// rollups are marked out of date, to be rebuilt on the next trends request.
+func (h *Handler) refreshRollups(ctx context.Context, userID string, times ...time.Time) {
+	user, err := h.store.Users.Get(ctx, userID)
+	if err != nil {
+		log.Printf("Could not refresh activity rollups for user %s: %v", userID, err)
+		return
+	}
+
+	unlock := h.rollupLocks.lock(user.ID)
+	defer unlock()
+
+	loc := user.Location()
+	zone, err := h.store.Rollups.Zone(ctx, user.ID)
+	if err != nil {
+		log.Printf("Could not refresh activity rollups for user %s: %v", userID, err)
+		return
+	}
+	if zone != loc.String() {
+		return // Not built yet, or about to be rebuilt
+	}
+
+	done := make(map[string]bool)
+	for _, t := range times {
+		day := startOfDay(t, loc)
+		date := localDate(day, loc)
+		if done[date] {
+			continue
+		}
+		done[date] = true
+
+		if err := h.refreshRollupDay(ctx, user.ID, date, day, loc); err != nil {
+			log.Printf("Could not refresh activity rollups for user %s on %s: %v", user.ID, date, err)
+
+			if err := h.store.Rollups.SetZone(ctx, user.ID, ""); err != nil {
+				log.Printf("Could not mark activity rollups out of date for user %s: %v", user.ID, err)
+			}
+			return
+		}
+	}
+}
+
+func (h *Handler) refreshRollupDay(ctx context.Context, userID, date string, day time.Time, loc *time.Location) error {
+	activities, err := h.store.Activities.List(ctx, repository.ActivityFilter{
+		UserID: userID,
+		From:   day,
+		To:     day.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		return err
+	}
+	return h.store.Rollups.ReplaceDay(ctx, userID, date, rollupDays(activities, loc)[date])
+}
+
+// rollupDays aggregates activities per day of loc, type and canonical unit.
+func rollupDays(activities []models.Activity, loc *time.Location) map[string][]models.ActivityRollup {
+	type key struct{ date, activityType, unit string }
+	byKey := make(map[key]*models.ActivityRollup)
+	var keys []key
+
+	now := time.Now()
+	for _, activity := range activities {
+		unit := activity.Unit
+		if t, ok := catalog.Lookup(activity.Type); ok {
+			unit = t.Canonical(unit)
+		}
+
+		k := key{localDate(activity.Date, loc), activity.Type, unit}
+		rollup, ok := byKey[k]
+		if !ok {
+			rollup = &models.ActivityRollup{
+				UserID:    activity.UserID,
+				Type:      activity.Type,
+				Unit:      unit,
+				Date:      k.date,
+				Min:       activity.Value,
+				Max:       activity.Value,
+				UpdatedAt: now,
+			}
+			byKey[k] = rollup
+			keys = append(keys, k)
+		}
+
+		rollup.Count++
+		rollup.Sum += activity.Value
+		rollup.Min = math.Min(rollup.Min, activity.Value)
+		rollup.Max = math.Max(rollup.Max, activity.Value)
+	}
+
+	days := make(map[string][]models.ActivityRollup)
+	for _, k := range keys {
+		days[k.date] = append(days[k.date], *byKey[k])
+	}
+	return days
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"log"
+
+	"orchestrator-service/models"
+	"orchestrator-service/triage"
+)
+
+// triage screens a message before it reaches the advisor. Hits are logged
+// so flagged conversations can be found for review.
+func (h *Handler) triage(session *models.ChatSession, text string) *triage.Result {
+	result := h.Triage.Classify(text)
+	if result != nil {
+		log.Printf("TRIAGE: %s message from user %s in session %s (rule %s)",
+			result.Category, session.UserID, session.ID, result.Rule)
+	}
+	return result
+}
+
+// flagMessages marks messages with the triage outcome, if any.
+func flagMessages(result *triage.Result, messages ...*models.ChatMessage) {
+	if result == nil {
+		return
+	}
+	for _, message := range messages {
+		message.Flag = result.Category
+		message.FlagRule = result.Rule
+	}
+}
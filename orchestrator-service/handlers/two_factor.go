@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"orchestrator-service/models"
+	"orchestrator-service/ratelimit"
+	"orchestrator-service/repository"
+	"orchestrator-service/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	totpIssuer        = "Health Advisor"
+	recoveryCodeCount = 10
+
+	// maxChallengeAttempts is how many wrong codes a login challenge takes
+	// before it is revoked and the user has to enter their password again.
+	maxChallengeAttempts = 3
+)
+
+// SetupTwoFactor starts TOTP enrollment by generating a secret for the
+// authenticator app. Nothing changes for login until VerifyTwoFactor
+// confirms the app produces valid codes.
+func (h *Handler) SetupTwoFactor(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+
+	ctx := context.Background()
+
+	user, err := h.store.Users.Get(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch user"})
+		return
+	}
+
+	if user.Settings.TwoFactorAuth {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate secret"})
+		return
+	}
+
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+	user.UpdatedAt = time.Now()
+
+	if err := h.store.Users.Save(ctx, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not save secret"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":     secret,
+		"otpauthUri": utils.TOTPURI(totpIssuer, user.Email, secret),
+	})
+}
+
+// VerifyTwoFactor completes enrollment with a code from the authenticator
+// app, turns two-factor authentication on and returns the recovery codes.
+// The codes are only ever shown here.
+func (h *Handler) VerifyTwoFactor(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+
+	user, err := h.store.Users.Get(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch user"})
+		return
+	}
+
+	if user.Settings.TwoFactorAuth {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+	if user.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor setup has not been started"})
+		return
+	}
+
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, req.Code, time.Now())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate recovery codes"})
+		return
+	}
+
+	user.RecoveryCodes = make([]string, len(codes))
+	for i, code := range codes {
+		user.RecoveryCodes[i] = utils.HashToken(code)
+	}
+	user.TOTPLastStep = step
+	user.Settings.TwoFactorAuth = true
+	user.UpdatedAt = time.Now()
+
+	if err := h.store.Users.Save(ctx, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not enable two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Two-factor authentication enabled",
+		"recoveryCodes": codes,
+	})
+}
+
+// DisableTwoFactor turns two-factor authentication off. It takes a current
+// TOTP or recovery code so a stolen session alone cannot remove it.
+func (h *Handler) DisableTwoFactor(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+
+	user, err := h.store.Users.Get(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch user"})
+		return
+	}
+
+	if !user.Settings.TwoFactorAuth {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+
+	if !checkSecondFactor(user, req.Code) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	user.Settings.TwoFactorAuth = false
+	user.TOTPSecret = ""
+	user.TOTPLastStep = 0
+	user.RecoveryCodes = nil
+	user.UpdatedAt = time.Now()
+
+	if err := h.store.Users.Save(ctx, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not disable two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// TwoFactorLogin is the second step of login: it exchanges the challenge
+// token from Login plus a TOTP or recovery code for real tokens.
+func (h *Handler) TwoFactorLogin(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, err := utils.VerifyToken(req.ChallengeToken)
+	if err != nil || claims.Purpose != utils.PurposeTwoFactor {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge"})
+		return
+	}
+
+	ctx := context.Background()
+
+	// Challenge tokens are single use
+	used, err := h.store.RevokedTokens.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if used {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge"})
+		return
+	}
+
+	user, err := h.store.Users.Get(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if !user.Settings.TwoFactorAuth || !checkSecondFactor(user, req.Code) {
+		h.challengeFailed(c, claims)
+		return
+	}
+
+	// Persist the consumed step or recovery code before handing out tokens
+	if err := h.store.Users.Save(ctx, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if err := h.store.RevokedTokens.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	tokens, err := h.issueTokens(c, user, req.DeviceID, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate token"})
+		return
+	}
+
+	tokens["message"] = "Login successful"
+	tokens["user"] = gin.H{
+		"id":       user.ID,
+		"email":    user.Email,
+		"fullName": user.FullName,
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// challengeFailed counts a wrong code against the challenge and revokes it
+// once it has taken maxChallengeAttempts.
+func (h *Handler) challengeFailed(c *gin.Context, claims *utils.Claims) {
+	ctx := context.Background()
+
+	entry, err := h.Attempts.Increment(ctx, "2fa-challenge:"+claims.ID, utils.ChallengeTokenTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if entry.Count < maxChallengeAttempts {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	if err := h.store.RevokedTokens.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	h.Attempts.Delete(ctx, "2fa-challenge:"+claims.ID)
+	c.JSON(http.StatusUnauthorized, gin.H{"error": "Too many invalid codes, please log in again"})
+}
+
+// ChallengeUser keys rate limits and lockouts of the second login step by
+// the user the challenge token was issued to, so failures add up across
+// challenges and addresses. Invalid tokens are left to the handler.
+func ChallengeUser(c *gin.Context) string {
+	claims, err := utils.VerifyToken(ratelimit.JSONValue(c, "challengeToken"))
+	if err != nil || claims.Purpose != utils.PurposeTwoFactor {
+		return ""
+	}
+	return claims.UserID
+}
+
+// checkSecondFactor accepts a TOTP code that has not been used yet or an
+// unused recovery code. On success it records the consumption on user; the
+// caller must save it.
+func checkSecondFactor(user *models.User, code string) bool {
+	if step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now()); ok {
+		if step <= user.TOTPLastStep {
+			return false
+		}
+		user.TOTPLastStep = step
+		return true
+	}
+
+	hash := utils.HashToken(strings.ToLower(strings.TrimSpace(code)))
+	for i, stored := range user.RecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(stored), []byte(hash)) == 1 {
+			user.RecoveryCodes = append(user.RecoveryCodes[:i], user.RecoveryCodes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
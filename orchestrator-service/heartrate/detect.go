@@ -0,0 +1,127 @@
+package heartrate
+
+import (
+	"sort"
+	"time"
+
+	"orchestrator-service/catalog"
+	"orchestrator-service/models"
+)
+
+// A run of readings is sustained once it has MinReadings readings spanning
+// at least MinDuration; a single reading may be a measuring error or a
+// brief spike. Readings further apart than MaxGap belong to separate runs.
+const (
+	MinReadings = 2
+	MinDuration = 10 * time.Minute
+	MaxGap      = 2 * time.Hour
+)
+
+// Recovery is how long after exercise the heart rate is still expected to
+// be raised.
+const Recovery = 30 * time.Minute
+
+// assumedExercise is the length assumed for exercise logged in kcal.
+const assumedExercise = time.Hour
+
+// Window is a span of time during which heart rate readings are not
+// judged, such as exercise.
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// ExerciseWindows turns exercise activities into the windows they and the
+// recovery after them cover. Exercise logged in kcal is assumed to last an
+// hour.
+func ExerciseWindows(activities []models.Activity) []Window {
+	exercise, _ := catalog.Lookup("exercise")
+
+	var windows []Window
+	for _, activity := range activities {
+		if activity.Type != "exercise" {
+			continue
+		}
+		length := assumedExercise
+		if exercise.Canonical(activity.Unit) == "min" {
+			length = time.Duration(activity.Value * float64(time.Minute))
+		}
+		windows = append(windows, Window{Start: activity.Date, End: activity.Date.Add(length + Recovery)})
+	}
+	return windows
+}
+
+func during(windows []Window, t time.Time) bool {
+	for _, w := range windows {
+		if !t.Before(w.Start) && !t.After(w.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// Episode is a sustained run of readings beyond one threshold.
+type Episode struct {
+	Kind      string // models.Tachycardia or models.Bradycardia
+	Threshold float64
+	Peak      float64 // Reading furthest past the threshold
+	Readings  int
+	Start     time.Time
+	End       time.Time
+}
+
+// Detect finds the sustained episodes among readings. Readings during
+// exercise are skipped without ending a run; a reading within range ends
+// it.
+func Detect(readings []models.Activity, thresholds Thresholds, exercise []Window) []Episode {
+	sorted := make([]models.Activity, len(readings))
+	copy(sorted, readings)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	var (
+		episodes []Episode
+		run      *Episode
+	)
+	end := func() {
+		if run != nil && run.Readings >= MinReadings && run.End.Sub(run.Start) >= MinDuration {
+			episodes = append(episodes, *run)
+		}
+		run = nil
+	}
+
+	for _, reading := range sorted {
+		if reading.Type != "heart_rate" || during(exercise, reading.Date) {
+			continue
+		}
+
+		var kind string
+		switch {
+		case reading.Value > thresholds.High:
+			kind = models.Tachycardia
+		case reading.Value < thresholds.Low:
+			kind = models.Bradycardia
+		default:
+			end()
+			continue
+		}
+
+		if run != nil && (run.Kind != kind || reading.Date.Sub(run.End) > MaxGap) {
+			end()
+		}
+		if run == nil {
+			run = &Episode{Kind: kind, Threshold: thresholds.High, Peak: reading.Value, Start: reading.Date}
+			if kind == models.Bradycardia {
+				run.Threshold = thresholds.Low
+			}
+		}
+
+		run.Readings++
+		run.End = reading.Date
+		if (kind == models.Tachycardia && reading.Value > run.Peak) || (kind == models.Bradycardia && reading.Value < run.Peak) {
+			run.Peak = reading.Value
+		}
+	}
+	end()
+
+	return episodes
+}
@@ -0,0 +1,107 @@
+// Package heartrate analyses the heart rate readings users log: it works
+// out their resting baseline and finds sustained runs of readings too fast
+// or too slow for their age. It flags readings worth a second look; it does
+// not diagnose anything.
+package heartrate
+
+import (
+	"sort"
+	"time"
+
+	"orchestrator-service/models"
+)
+
+// Range is the normal resting heart rate, in bpm, up to MaxAge.
+type Range struct {
+	MaxAge int     `json:"maxAge"`
+	Low    float64 `json:"low"`
+	High   float64 `json:"high"`
+}
+
+// Ranges are the resting ranges by age group, youngest first. Adults below
+// 60 bpm are common among the fit, so the adult range starts at 50.
+var Ranges = []Range{
+	{MaxAge: 2, Low: 80, High: 140},
+	{MaxAge: 5, Low: 75, High: 130},
+	{MaxAge: 12, Low: 65, High: 120},
+	{MaxAge: 17, Low: 55, High: 105},
+}
+
+// AdultRange applies past the last of Ranges and when the age is unknown.
+var AdultRange = Range{Low: 50, High: 100}
+
+// RangeFor finds the resting range for an age in years; 0 means unknown.
+func RangeFor(age int) Range {
+	if age > 0 {
+		for _, r := range Ranges {
+			if age <= r.MaxAge {
+				return r
+			}
+		}
+	}
+	return AdultRange
+}
+
+// Thresholds are the bpm below and above which readings are out of range.
+type Thresholds struct {
+	Low  float64 `json:"low"`
+	High float64 `json:"high"`
+}
+
+// ThresholdsFor takes the age range and lowers its bottom for users whose
+// resting rate is naturally low, to baselineMargin under their baseline.
+// Without a baseline the age range applies as is.
+func ThresholdsFor(age int, baseline *Baseline) Thresholds {
+	r := RangeFor(age)
+	t := Thresholds{Low: r.Low, High: r.High}
+	if baseline != nil && baseline.Resting-baselineMargin < t.Low {
+		t.Low = baseline.Resting - baselineMargin
+	}
+	return t
+}
+
+// baselineMargin is how far below their baseline a user's readings may
+// fall before a low threshold under the age range catches them.
+const baselineMargin = 10
+
+// Baseline is a user's resting heart rate: the median of their lowest
+// reading on each day.
+type Baseline struct {
+	Resting float64 `json:"resting"` // bpm
+	Days    int     `json:"days"`    // Days with readings it was taken from
+}
+
+// MinBaselineDays is how many days of readings a baseline needs.
+const MinBaselineDays = 3
+
+// NewBaseline works out the baseline from readings, with days taken in
+// loc. Readings during exercise are left out; taking the median keeps a
+// few unusual days from shifting it. It returns nil with fewer than
+// MinBaselineDays days of readings.
+func NewBaseline(readings []models.Activity, exercise []Window, loc *time.Location) *Baseline {
+	lowest := make(map[string]float64)
+	for _, reading := range readings {
+		if during(exercise, reading.Date) {
+			continue
+		}
+		day := reading.Date.In(loc).Format("2006-01-02")
+		if value, ok := lowest[day]; !ok || reading.Value < value {
+			lowest[day] = reading.Value
+		}
+	}
+	if len(lowest) < MinBaselineDays {
+		return nil
+	}
+
+	values := make([]float64, 0, len(lowest))
+	for _, value := range lowest {
+		values = append(values, value)
+	}
+	sort.Float64s(values)
+
+	resting := values[len(values)/2]
+	if len(values)%2 == 0 {
+		resting = (values[len(values)/2-1] + resting) / 2
+	}
+	return &Baseline{Resting: resting, Days: len(values)}
+}
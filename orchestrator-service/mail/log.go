@@ -0,0 +1,15 @@
+package mail
+
+import (
+	"context"
+	"log"
+)
+
+// LogSender writes messages to the server log instead of delivering them.
+// Only meant for development: reset links end up in the log.
+type LogSender struct{}
+
+func (LogSender) Send(ctx context.Context, msg Message) error {
+	log.Printf("[mail] to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -1,12 +1,23 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"orchestrator-service/database"
 	"orchestrator-service/handlers"
+	"orchestrator-service/mail"
 	"orchestrator-service/middleware"
+	"orchestrator-service/rag"
+	"orchestrator-service/ratelimit"
+	"orchestrator-service/repository"
+	"orchestrator-service/repository/firestorerepo"
+	"orchestrator-service/repository/memrepo"
+	"orchestrator-service/repository/sqlrepo"
+	"orchestrator-service/triage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -17,37 +28,183 @@ func main() {
 		log.Println("No .env file found")
 	}
 
-	database.InitFirebase()
-	defer database.CloseFirebase()
+	ctx := context.Background()
+
+	var (
+		store  *repository.Store
+		google handlers.GoogleVerifier
+	)
+
+	// STORAGE_BACKEND selects where data lives: "firestore" (default),
+	// "postgres" or "sqlite" (both configured through DATABASE_URL), or
+	// "memory" for throwaway local runs.
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", "firestore":
+		fb, err := database.InitFirebase(ctx)
+		if err != nil {
+			log.Fatal("Failed to connect to Firebase: ", err)
+		}
+		defer fb.Close()
+
+		store = firestorerepo.NewStore(fb.Client)
+		google = fb
+	case "postgres", "sqlite":
+		dsn := os.Getenv("DATABASE_URL")
+		if dsn == "" {
+			if backend == "postgres" {
+				log.Fatal("DATABASE_URL environment variable is required for the postgres backend")
+			}
+			dsn = "health-advisor.db"
+		}
+
+		db, err := sqlrepo.Open(ctx, sqlrepo.Dialect(backend), dsn)
+		if err != nil {
+			log.Fatalf("Failed to open %s database: %v", backend, err)
+		}
+		defer db.Close()
+
+		log.Printf("Connected to %s database", backend)
+		store = sqlrepo.NewStore(db, sqlrepo.Dialect(backend))
+	case "memory":
+		log.Println("Using in-memory storage; data will not survive a restart")
+		store = memrepo.NewStore()
+	default:
+		log.Fatalf("Unknown STORAGE_BACKEND %q", backend)
+	}
+
+	h := handlers.New(store)
+	if err := h.BackfillAchievements(ctx); err != nil {
+		log.Fatal("Failed to award achievements: ", err)
+	}
+	h.Google = google
+	h.RAG = rag.NewClient(rag.ConfigFromEnv())
+	h.Mailer = mail.NewFromEnv()
+	if path := os.Getenv("TRIAGE_RULES"); path != "" {
+		classifier, err := triage.LoadFile(path)
+		if err != nil {
+			log.Fatal("Failed to load triage rules: ", err)
+		}
+		h.Triage = classifier
+	}
+	if appURL := os.Getenv("APP_URL"); appURL != "" {
+		h.AppURL = appURL
+	}
+	if apiURL := os.Getenv("API_URL"); apiURL != "" {
+		h.APIURL = apiURL
+	}
 
 	router := gin.Default()
 
+	// Client IPs key the rate limits, so X-Forwarded-For is only believed
+	// from the proxies listed in TRUSTED_PROXIES (comma separated IPs or
+	// CIDRs); without any the connection's own address is used
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	if err := router.SetTrustedProxies(proxies); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES: ", err)
+	}
+
 	router.Use(middleware.CORS())
 
+	// Throttling for the unauthenticated auth endpoints
+	limits := ratelimit.NewMemoryStore()
+	h.Attempts = limits
+	perIP := ratelimit.Limit(limits, ratelimit.Rule{
+		Name: "auth-ip", Limit: 30, Window: time.Minute, Key: ratelimit.ClientIP,
+	})
+	perAccount := ratelimit.Limit(limits, ratelimit.Rule{
+		Name: "auth-account", Limit: 10, Window: 15 * time.Minute, Key: ratelimit.JSONField("email"),
+	})
+	lockoutPolicy := ratelimit.LockoutPolicy{
+		Threshold: 5,
+		BaseDelay: time.Minute,
+		MaxDelay:  time.Hour,
+		Window:    24 * time.Hour,
+	}
+	accountLockout := ratelimit.NewLockout(limits, "login-account", lockoutPolicy).Middleware(ratelimit.JSONField("email"))
+	// Many users may share an address behind NAT, so an address gets more
+	// failures than an account before it is locked out
+	ipLockoutPolicy := lockoutPolicy
+	ipLockoutPolicy.Threshold = 20
+	ipLockout := ratelimit.NewLockout(limits, "login-ip", ipLockoutPolicy).Middleware(ratelimit.ClientIP)
+	perChallengeUser := ratelimit.Limit(limits, ratelimit.Rule{
+		Name: "auth-2fa-user", Limit: 10, Window: 15 * time.Minute, Key: handlers.ChallengeUser,
+	})
+	twoFactorLockout := ratelimit.NewLockout(limits, "login-2fa", lockoutPolicy).Middleware(handlers.ChallengeUser)
+
 	// Auth routes
-	router.POST("/api/auth/login", handlers.Login)
-	router.POST("/api/auth/register", handlers.Register)
-	router.POST("/api/auth/google", handlers.GoogleAuth)
+	router.POST("/api/auth/login", perIP, perAccount, ipLockout, accountLockout, h.Login)
+	router.POST("/api/auth/register", perIP, perAccount, h.Register)
+	router.POST("/api/auth/google", perIP, h.GoogleAuth)
+	router.POST("/api/auth/refresh", perIP, h.RefreshToken)
+	router.POST("/api/auth/2fa/login", perIP, perChallengeUser, ipLockout, twoFactorLockout, h.TwoFactorLogin)
+	router.POST("/api/auth/forgot-password", perIP, perAccount, h.ForgotPassword)
+	router.POST("/api/auth/reset-password", perIP, h.ResetPassword)
+	router.GET("/api/auth/verify-email", perIP, h.VerifyEmail)
 
 	// Protected routes
 	auth := router.Group("/api")
-	auth.Use(middleware.AuthMiddleware())
+	auth.Use(middleware.AuthMiddleware(store.RevokedTokens))
 	{
-		auth.GET("/profile", handlers.GetProfile)
-		auth.PUT("/profile", handlers.UpdateProfile)
+		auth.POST("/auth/logout", h.Logout)
+		auth.POST("/auth/2fa/setup", h.SetupTwoFactor)
+		auth.POST("/auth/2fa/verify", h.VerifyTwoFactor)
+		auth.POST("/auth/2fa/disable", h.DisableTwoFactor)
+		auth.POST("/auth/verify-email/resend", h.ResendVerificationEmail)
+
+		auth.GET("/profile", h.GetProfile)
+		auth.PUT("/profile", h.UpdateProfile)
+
+		auth.GET("/activity", h.GetActivity)
+		auth.POST("/activity", h.CreateActivity)
+		auth.PUT("/activity/:id", h.UpdateActivity)
+		auth.PATCH("/activity/:id", h.PatchActivity)
+		auth.DELETE("/activity/:id", h.DeleteActivity)
 
-		auth.GET("/activity", handlers.GetActivity)
-		auth.POST("/activity", handlers.CreateActivity)
-		auth.GET("/activity/summary", handlers.GetActivitySummary)
+		auth.GET("/goals", h.ListGoals)
+		auth.POST("/goals", h.CreateGoal)
+		auth.GET("/goals/:id", h.GetGoal)
+		auth.PUT("/goals/:id", h.UpdateGoal)
+		auth.DELETE("/goals/:id", h.DeleteGoal)
+		auth.GET("/goals/:id/history", h.GetGoalHistory)
 
-		auth.GET("/health-records", handlers.GetHealthRecords)
-		auth.POST("/health-records", handlers.CreateHealthRecord)
-		auth.DELETE("/health-records/:id", handlers.DeleteHealthRecord)
+		auth.GET("/achievements", h.GetAchievements)
+		auth.GET("/activity/summary", h.GetActivitySummary)
+		auth.GET("/activity/types", h.GetActivityTypes)
+		auth.GET("/activity/trends", h.GetActivityTrends)
 
-		auth.POST("/chat", handlers.SendMessage)
-		auth.GET("/chat/history", handlers.GetChatHistory)
+		auth.GET("/heart-rate/baseline", h.GetHeartRateBaseline)
+		auth.GET("/heart-rate/alerts", h.ListHeartRateAlerts)
+		auth.POST("/heart-rate/alerts/:id/acknowledge", h.AcknowledgeHeartRateAlert)
 
-		auth.PUT("/settings", handlers.UpdateSettings)
+		auth.GET("/health-records", h.GetHealthRecords)
+		auth.POST("/health-records", h.CreateHealthRecord)
+		auth.DELETE("/health-records/:id", h.DeleteHealthRecord)
+
+		auth.POST("/chat", h.SendMessage)
+		auth.POST("/chat/stream", h.StreamMessage)
+		auth.GET("/chat/history", h.GetChatHistory)
+		auth.GET("/chat/search", h.SearchChat)
+		auth.POST("/chat/messages/:id/feedback", h.SubmitFeedback)
+		auth.GET("/chat/feedback/stats", h.GetFeedbackStats)
+		auth.GET("/chat/sessions", h.ListChatSessions)
+		auth.POST("/chat/sessions", h.CreateChatSession)
+		auth.GET("/chat/sessions/:id", h.GetChatSession)
+		auth.PATCH("/chat/sessions/:id", h.RenameChatSession)
+		auth.DELETE("/chat/sessions/:id", h.DeleteChatSession)
+
+		auth.PUT("/settings", h.UpdateSettings)
+	}
+
+	// Operator routes, enabled by setting ADMIN_API_KEY
+	admin := router.Group("/api/admin")
+	admin.Use(perIP, middleware.AdminKey(os.Getenv("ADMIN_API_KEY")))
+	{
+		admin.GET("/chat/feedback/stats", h.GetAllFeedbackStats)
 	}
 
 	port := os.Getenv("PORT")
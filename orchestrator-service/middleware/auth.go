@@ -2,15 +2,17 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
+	"orchestrator-service/repository"
 	"orchestrator-service/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(revoked repository.RevokedTokenRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -27,14 +29,28 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		claims, err := utils.VerifyToken(tokenString)
-		if err != nil {
+		if err != nil || claims.ID == "" || claims.ExpiresAt == nil || claims.Purpose != "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
 
+		isRevoked, err := revoked.IsRevoked(context.Background(), claims.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not verify token"})
+			c.Abort()
+			return
+		}
+		if isRevoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+
 		c.Set("userId", claims.UserID)
 		c.Set("email", claims.Email)
+		c.Set("tokenId", claims.ID)
+		c.Set("tokenExpiresAt", claims.ExpiresAt.Time)
 		c.Next()
 	}
 }
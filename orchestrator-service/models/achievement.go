@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// Achievement is a badge a user has earned. Badges are never taken away,
+// even if the activities that earned them are later edited or deleted.
+type Achievement struct {
+	UserID   string    `firestore:"userId" json:"userId"`
+	BadgeID  string    `firestore:"badgeId" json:"badgeId"` // ID of an achievements.Rule
+	EarnedAt time.Time `firestore:"earnedAt" json:"earnedAt"`
+}
@@ -5,23 +5,138 @@ import "time"
 type Activity struct {
 	ID          string    `firestore:"id" json:"id"`
 	UserID      string    `firestore:"userId" json:"userId"`
-	Type        string    `firestore:"type" json:"type"` // "steps", "heart_rate", "water", "sleep", "exercise"
+	Type        string    `firestore:"type" json:"type"` // One of catalog.Types: "steps", "heart_rate", "water", "sleep", "exercise"
 	Value       float64   `firestore:"value" json:"value"`
 	Unit        string    `firestore:"unit" json:"unit"`
 	Description string    `firestore:"description" json:"description"`
 	Date        time.Time `firestore:"date" json:"date"`
 	CreatedAt   time.Time `firestore:"createdAt" json:"createdAt"`
+
+	// Estimate is worked out from the user's profile whenever an exercise
+	// activity is returned; it is never stored.
+	Estimate *ActivityEstimate `firestore:"-" json:"estimate,omitempty"`
+}
+
+// ActivityEstimate holds the figures derived from an exercise activity.
+type ActivityEstimate struct {
+	Exercise string  `json:"exercise"` // Kind of exercise recognised in the description
+	MET      float64 `json:"met"`      // Its intensity, in multiples of the energy spent at rest
+	Calories float64 `json:"calories"` // Active kcal, on top of the resting rate
+}
+
+// ActivityPatch is a partial update of an activity; nil fields are left
+// unchanged.
+type ActivityPatch struct {
+	Type        *string    `json:"type"`
+	Value       *float64   `json:"value"`
+	Unit        *string    `json:"unit"`
+	Description *string    `json:"description"`
+	Date        *time.Time `json:"date"`
+}
+
+// ActivityDay is one day of a user's activity history; Date is the day in
+// the user's time zone as YYYY-MM-DD.
+type ActivityDay struct {
+	Date       string     `json:"date"`
+	Activities []Activity `json:"activities"`
+}
+
+// ActivityRollup aggregates a user's activities of one type and unit on
+// one day, as seen in the user's time zone when it was computed.
+type ActivityRollup struct {
+	UserID    string    `firestore:"userId" json:"userId"`
+	Type      string    `firestore:"type" json:"type"`
+	Unit      string    `firestore:"unit" json:"unit"` // Canonical unit from the catalog
+	Date      string    `firestore:"date" json:"date"` // YYYY-MM-DD
+	Count     int       `firestore:"count" json:"count"`
+	Sum       float64   `firestore:"sum" json:"sum"`
+	Min       float64   `firestore:"min" json:"min"`
+	Max       float64   `firestore:"max" json:"max"`
+	UpdatedAt time.Time `firestore:"updatedAt" json:"updatedAt"`
+}
+
+// TrendBucket aggregates one day, week or month of a trend. Start and End
+// are inclusive dates; Avg, Min and Max are nil for a bucket without
+// entries.
+type TrendBucket struct {
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+	Count int      `json:"count"` // Activities logged
+	Days  int      `json:"days"`  // Days with at least one activity
+	Sum   float64  `json:"sum"`
+	Avg   *float64 `json:"avg"` // Per activity
+	Min   *float64 `json:"min"`
+	Max   *float64 `json:"max"`
 }
 
+// TrendSeries is the trend of one activity type in one unit.
+type TrendSeries struct {
+	Type    string        `json:"type"`
+	Unit    string        `json:"unit"`
+	Buckets []TrendBucket `json:"buckets"`
+}
+
+// ActivitySummary holds today's figures alongside totals for the trailing
+// seven days (today included). Distance and calories are estimated from
+// the user's profile, as shown in Energy.
 type ActivitySummary struct {
-	Steps          int     `json:"steps"`
-	StepsGoal      int     `json:"stepsGoal"`
-	HeartRate      int     `json:"heartRate"`
-	Water          int     `json:"water"`
-	WaterGoal      int     `json:"waterGoal"`
-	Sleep          float64 `json:"sleep"`
-	SleepGoal      float64 `json:"sleepGoal"`
-	TotalSteps     int     `json:"totalSteps"`
-	ActiveMinutes  int     `json:"activeMinutes"`
-	CaloriesBurned int     `json:"caloriesBurned"`
+	Steps          int            `json:"steps"`
+	StepsGoal      int            `json:"stepsGoal"`
+	HeartRate      int            `json:"heartRate"`    // Latest reading of the week
+	AvgHeartRate   int            `json:"avgHeartRate"` // Average over the week
+	Water          int            `json:"water"`
+	WaterGoal      int            `json:"waterGoal"`
+	Sleep          float64        `json:"sleep"`
+	SleepGoal      float64        `json:"sleepGoal"`
+	TotalSteps     int            `json:"totalSteps"`
+	TotalWater     int            `json:"totalWater"`
+	TotalSleep     float64        `json:"totalSleep"`
+	ActiveMinutes  int            `json:"activeMinutes"`  // Today's exercise logged in minutes
+	CaloriesBurned int            `json:"caloriesBurned"` // Today's active kcal, walking and exercise
+	Distance       float64        `json:"distance"`       // km walked today
+	Energy         EnergyEstimate `json:"energy"`
+}
+
+// EnergyEstimate shows how the summary's distance and calories were
+// worked out, and the measurements they were based on.
+type EnergyEstimate struct {
+	Weight           float64  `json:"weight"` // kg
+	Height           float64  `json:"height"` // cm
+	Age              int      `json:"age"`
+	Gender           string   `json:"gender,omitempty"`  // "male" or "female"; empty uses the average of both
+	Assumed          []string `json:"assumed,omitempty"` // Profile fields missing and replaced by averages
+	BMR              int      `json:"bmr"`               // Resting kcal per day, by the Mifflin-St Jeor equation
+	StrideLength     float64  `json:"strideLength"`      // Metres, from height
+	WalkingCalories  int      `json:"walkingCalories"`   // Today's steps, at 0.5 kcal per kg per km
+	ExerciseCalories int      `json:"exerciseCalories"`  // Today's exercise, by MET or as logged in kcal
+	TotalCalories    int      `json:"totalCalories"`     // BMR plus today's active kcal
+}
+
+// ActivityGoals are the daily targets shown in the activity summary, taken
+// from the user's daily goals.
+type ActivityGoals struct {
+	Steps int     `firestore:"steps" json:"steps"`
+	Water int     `firestore:"water" json:"water"`
+	Sleep float64 `firestore:"sleep" json:"sleep"`
+}
+
+// DefaultActivityGoals are used for any goal the user has not set.
+var DefaultActivityGoals = ActivityGoals{
+	Steps: 10000,
+	Water: 8,
+	Sleep: 8,
+}
+
+// WithDefaults fills unset goals from DefaultActivityGoals.
+func (g ActivityGoals) WithDefaults() ActivityGoals {
+	if g.Steps == 0 {
+		g.Steps = DefaultActivityGoals.Steps
+	}
+	if g.Water == 0 {
+		g.Water = DefaultActivityGoals.Water
+	}
+	if g.Sleep == 0 {
+		g.Sleep = DefaultActivityGoals.Sleep
+	}
+	return g
 }
@@ -8,7 +8,12 @@ type ChatMessage struct {
 	Text      string    `firestore:"text" json:"text"`
 	Sender    string    `firestore:"sender" json:"sender"` // "user" or "ai"
 	Timestamp time.Time `firestore:"timestamp" json:"timestamp"`
-	SessionID string    `firestore:"sessionId,omitempty" json:"sessionId"` // Optional: for grouping chats into sessions
+	SessionID string    `firestore:"sessionId,omitempty" json:"sessionId"` // Groups messages into a ChatSession
+
+	// Set when triage caught an emergency or self-harm signal, on both the
+	// message and the urgent-care reply, so the conversation can be reviewed.
+	Flag     string `firestore:"flag,omitempty" json:"flag,omitempty"`         // Triage category, e.g. "emergency"
+	FlagRule string `firestore:"flagRule,omitempty" json:"flagRule,omitempty"` // Name of the rule that matched
 }
 
 type ChatHistoryItem struct {
@@ -18,12 +23,78 @@ type ChatHistoryItem struct {
 }
 
 type ChatRequest struct {
-	Message   string            `json:"message"`
-	SessionID string            `json:"sessionId"`
-	History   []ChatHistoryItem `json:"history"`
+	Message   string            `json:"message" binding:"required"`
+	SessionID string            `json:"sessionId"` // Empty starts a new session
+	History   []ChatHistoryItem `json:"history"`   // Deprecated: ignored, history is built from stored messages
 }
 
 type ChatResponse struct {
 	UserMessage ChatMessage `json:"userMessage"`
 	AiMessage   ChatMessage `json:"aiMessage"`
+	Session     ChatSession `json:"session"`
+}
+
+// ChatFeedback is a user's rating of one AI answer. There is at most one
+// per message; rating again replaces it.
+type ChatFeedback struct {
+	MessageID string    `firestore:"messageId" json:"messageId"`
+	UserID    string    `firestore:"userId" json:"userId"`
+	SessionID string    `firestore:"sessionId" json:"sessionId"`
+	Rating    string    `firestore:"rating" json:"rating"`                     // "up" or "down"
+	Reason    string    `firestore:"reason,omitempty" json:"reason,omitempty"` // One of FeedbackReasons
+	Comment   string    `firestore:"comment,omitempty" json:"comment,omitempty"`
+	CreatedAt time.Time `firestore:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `firestore:"updatedAt" json:"updatedAt"`
+}
+
+type ChatFeedbackRequest struct {
+	Rating  string `json:"rating" binding:"required,oneof=up down"`
+	Reason  string `json:"reason" binding:"omitempty,oneof=incorrect unhelpful unsafe off_topic too_long other"`
+	Comment string `json:"comment" binding:"max=2000"`
+}
+
+// FeedbackStats aggregates the feedback given in one time window.
+type FeedbackStats struct {
+	From         time.Time      `json:"from"`
+	To           time.Time      `json:"to"`
+	Total        int            `json:"total"`
+	Up           int            `json:"up"`
+	Down         int            `json:"down"`
+	PositiveRate float64        `json:"positiveRate"` // Up / Total, 0 when there is no feedback
+	Reasons      map[string]int `json:"reasons"`      // Counts of down-vote reasons
+}
+
+// HealthContext is the minimal profile and activity summary attached to
+// chat queries for users who opted in to personalized advice. Empty fields
+// are left out of the request.
+type HealthContext struct {
+	Age            int             `json:"age,omitempty"`
+	Gender         string          `json:"gender,omitempty"`
+	Height         float64         `json:"height,omitempty"` // cm
+	Weight         float64         `json:"weight,omitempty"` // kg
+	Allergies      string          `json:"allergies,omitempty"`
+	Medications    string          `json:"medications,omitempty"`
+	Conditions     string          `json:"conditions,omitempty"`
+	RecentActivity *RecentActivity `json:"recentActivity,omitempty"`
+}
+
+// RecentActivity summarizes the past week of logged activities.
+type RecentActivity struct {
+	AvgDailySteps int     `json:"avgDailySteps,omitempty"`
+	ActiveMinutes int     `json:"activeMinutes,omitempty"`
+	AvgSleep      float64 `json:"avgSleep,omitempty"` // Hours per night
+	AvgHeartRate  int     `json:"avgHeartRate,omitempty"`
+}
+
+type ChatSession struct {
+	ID           string    `firestore:"id" json:"id"`
+	UserID       string    `firestore:"userId" json:"userId"`
+	Title        string    `firestore:"title" json:"title"`
+	MessageCount int       `firestore:"messageCount" json:"messageCount"`
+	CreatedAt    time.Time `firestore:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time `firestore:"updatedAt" json:"updatedAt"` // Time of the last message
+}
+
+type ChatSessionRequest struct {
+	Title string `json:"title" binding:"max=120"`
 }
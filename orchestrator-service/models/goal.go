@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// Goal is a user's target for one activity type: a total to reach each
+// day or each week (weeks start on Monday) between StartDate and EndDate.
+// Dates are days in the user's time zone as YYYY-MM-DD; EndDate is
+// inclusive and empty for a goal that does not end.
+type Goal struct {
+	ID        string    `firestore:"id" json:"id"`
+	UserID    string    `firestore:"userId" json:"userId"`
+	Type      string    `firestore:"type" json:"type"`     // A catalog type whose values add up
+	Period    string    `firestore:"period" json:"period"` // "daily" or "weekly"
+	Target    float64   `firestore:"target" json:"target"`
+	Unit      string    `firestore:"unit" json:"unit"`
+	StartDate string    `firestore:"startDate" json:"startDate"`
+	EndDate   string    `firestore:"endDate" json:"endDate"`
+	CreatedAt time.Time `firestore:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `firestore:"updatedAt" json:"updatedAt"`
+}
+
+// ActiveOn reports whether the goal applies on date (YYYY-MM-DD).
+func (g *Goal) ActiveOn(date string) bool {
+	return g.StartDate <= date && (g.EndDate == "" || date <= g.EndDate)
+}
+
+// Overlaps reports whether two goals apply on at least one common day.
+func (g *Goal) Overlaps(other *Goal) bool {
+	return (g.EndDate == "" || other.StartDate <= g.EndDate) &&
+		(other.EndDate == "" || g.StartDate <= other.EndDate)
+}
+
+// GoalRequest creates or replaces a goal. Unit defaults to the type's
+// default unit and StartDate to today.
+type GoalRequest struct {
+	Type      string  `json:"type" binding:"required"`
+	Period    string  `json:"period" binding:"required,oneof=daily weekly"`
+	Target    float64 `json:"target" binding:"gt=0"`
+	Unit      string  `json:"unit"`
+	StartDate string  `json:"startDate"`
+	EndDate   string  `json:"endDate"`
+}
+
+// GoalChange records a goal as it was after being created or updated, or
+// just before being deleted.
+type GoalChange struct {
+	ID        string    `firestore:"id" json:"id"`
+	GoalID    string    `firestore:"goalId" json:"goalId"`
+	UserID    string    `firestore:"userId" json:"userId"`
+	Action    string    `firestore:"action" json:"action"` // "created", "updated" or "deleted"
+	Type      string    `firestore:"type" json:"type"`
+	Period    string    `firestore:"period" json:"period"`
+	Target    float64   `firestore:"target" json:"target"`
+	Unit      string    `firestore:"unit" json:"unit"`
+	StartDate string    `firestore:"startDate" json:"startDate"`
+	EndDate   string    `firestore:"endDate" json:"endDate"`
+	ChangedAt time.Time `firestore:"changedAt" json:"changedAt"`
+}
+
+// GoalProgress is how far a user has got towards a goal in one period.
+// Period dates are inclusive.
+type GoalProgress struct {
+	Goal
+	PeriodStart string  `json:"periodStart,omitempty"`
+	PeriodEnd   string  `json:"periodEnd,omitempty"`
+	Value       float64 `json:"value"`
+	Percent     float64 `json:"percent"` // Of the target, not capped at 100
+	Achieved    bool    `json:"achieved"`
+}
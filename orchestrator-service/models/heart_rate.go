@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Heart rate alert kinds and statuses
+const (
+	Tachycardia = "tachycardia" // Too fast
+	Bradycardia = "bradycardia" // Too slow
+
+	AlertOpen         = "open"
+	AlertAcknowledged = "acknowledged"
+	AlertWithdrawn    = "withdrawn" // Its readings were since deleted or changed
+)
+
+// HeartRateAlert records a sustained run of heart rate readings beyond one
+// of the user's thresholds. It is updated while the run grows.
+type HeartRateAlert struct {
+	ID             string     `firestore:"id" json:"id"`
+	UserID         string     `firestore:"userId" json:"userId"`
+	Kind           string     `firestore:"kind" json:"kind"`           // Tachycardia or Bradycardia
+	Threshold      float64    `firestore:"threshold" json:"threshold"` // bpm crossed
+	Baseline       float64    `firestore:"baseline" json:"baseline"`   // Resting bpm at the time; 0 when not known yet
+	Peak           float64    `firestore:"peak" json:"peak"`           // Reading furthest past the threshold
+	Readings       int        `firestore:"readings" json:"readings"`
+	Start          time.Time  `firestore:"start" json:"start"` // First reading of the run
+	End            time.Time  `firestore:"end" json:"end"`     // Last reading of the run
+	Status         string     `firestore:"status" json:"status"`
+	CreatedAt      time.Time  `firestore:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time  `firestore:"updatedAt" json:"updatedAt"`
+	AcknowledgedAt *time.Time `firestore:"acknowledgedAt" json:"acknowledgedAt,omitempty"`
+	NotifiedAt     *time.Time `firestore:"notifiedAt" json:"notifiedAt,omitempty"` // When the user was emailed about it
+}
@@ -0,0 +1,10 @@
+package models
+
+// Page is the envelope paginated listings answer with. NextCursor is
+// passed back as the cursor parameter to fetch the following page; it is
+// empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	HasMore    bool   `json:"hasMore"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// RefreshToken is the server-side record of an issued refresh token. Only a
+// hash of the token is stored; it doubles as the document ID.
+type RefreshToken struct {
+	ID         string    `firestore:"id" json:"id"`
+	UserID     string    `firestore:"userId" json:"userId"`
+	FamilyID   string    `firestore:"familyId" json:"familyId"` // Shared by every rotation of one login
+	DeviceID   string    `firestore:"deviceId" json:"deviceId"`
+	UserAgent  string    `firestore:"userAgent" json:"userAgent"`
+	CreatedAt  time.Time `firestore:"createdAt" json:"createdAt"`
+	ExpiresAt  time.Time `firestore:"expiresAt" json:"expiresAt"`
+	RevokedAt  time.Time `firestore:"revokedAt" json:"revokedAt"`
+	ReplacedBy string    `firestore:"replacedBy" json:"replacedBy"`
+}
+
+// Revoked reports whether the token has been rotated or logged out.
+func (t RefreshToken) Revoked() bool {
+	return !t.RevokedAt.IsZero()
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// ActionToken backs a single-use link sent by email. ID is the hash of the
+// random part of the token.
+type ActionToken struct {
+	ID        string    `firestore:"id" json:"id"`
+	UserID    string    `firestore:"userId" json:"userId"`
+	Purpose   string    `firestore:"purpose" json:"purpose"`
+	Email     string    `firestore:"email" json:"email"` // Address the token was sent to
+	CreatedAt time.Time `firestore:"createdAt" json:"createdAt"`
+	ExpiresAt time.Time `firestore:"expiresAt" json:"expiresAt"`
+	UsedAt    time.Time `firestore:"usedAt" json:"usedAt"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
@@ -3,24 +3,58 @@ package models
 import "time"
 
 type User struct {
-	ID           string       `firestore:"id" json:"id"`
-	Email        string       `firestore:"email" json:"email"`
-	Password     string       `firestore:"password,omitempty" json:"-"`
-	FullName     string       `firestore:"fullName" json:"fullName"`
-	DateOfBirth  time.Time    `firestore:"dateOfBirth" json:"dateOfBirth"`
-	Gender       string       `firestore:"gender" json:"gender"`
-	Height       float64      `firestore:"height" json:"height"`
-	Weight       float64      `firestore:"weight" json:"weight"`
-	BloodType    string       `firestore:"bloodType" json:"bloodType"`
-	Allergies    string       `firestore:"allergies" json:"allergies"`
-	Medications  string       `firestore:"medications" json:"medications"`
-	Conditions   string       `firestore:"conditions" json:"conditions"`
-	ProfileImage string       `firestore:"profileImage" json:"profileImage"`
-	Settings     UserSettings `firestore:"settings" json:"settings"`
-	CreatedAt    time.Time    `firestore:"createdAt" json:"createdAt"`
-	UpdatedAt    time.Time    `firestore:"updatedAt" json:"updatedAt"`
-	Provider     string       `firestore:"provider" json:"provider"` // "email" or "google"
-	GoogleID     string       `firestore:"googleId,omitempty" json:"-"`
+	ID            string       `firestore:"id" json:"id"`
+	Email         string       `firestore:"email" json:"email"`
+	EmailVerified bool         `firestore:"emailVerified" json:"emailVerified"`
+	Password      string       `firestore:"password,omitempty" json:"-"`
+	FullName      string       `firestore:"fullName" json:"fullName"`
+	DateOfBirth   time.Time    `firestore:"dateOfBirth" json:"dateOfBirth"`
+	Gender        string       `firestore:"gender" json:"gender"`
+	Height        float64      `firestore:"height" json:"height"`
+	Weight        float64      `firestore:"weight" json:"weight"`
+	BloodType     string       `firestore:"bloodType" json:"bloodType"`
+	Allergies     string       `firestore:"allergies" json:"allergies"`
+	Medications   string       `firestore:"medications" json:"medications"`
+	Conditions    string       `firestore:"conditions" json:"conditions"`
+	ProfileImage  string       `firestore:"profileImage" json:"profileImage"`
+	Settings      UserSettings `firestore:"settings" json:"settings"`
+	CreatedAt     time.Time    `firestore:"createdAt" json:"createdAt"`
+	UpdatedAt     time.Time    `firestore:"updatedAt" json:"updatedAt"`
+	Provider      string       `firestore:"provider" json:"provider"` // "email" or "google"
+	Timezone      string       `firestore:"timezone" json:"timezone"` // IANA name such as "Europe/Bucharest"; empty means UTC
+	GoogleID      string       `firestore:"googleId,omitempty" json:"-"`
+
+	// Two-factor state. TOTPSecret is set during enrollment and only takes
+	// effect once Settings.TwoFactorAuth is on.
+	TOTPSecret    string   `firestore:"totpSecret,omitempty" json:"-"`
+	TOTPLastStep  int64    `firestore:"totpLastStep,omitempty" json:"-"`  // Last accepted time step, to stop replays
+	RecoveryCodes []string `firestore:"recoveryCodes,omitempty" json:"-"` // SHA-256 hashes of unused codes
+}
+
+// Location is the user's time zone, used to tell where their days start
+// and end. It falls back to UTC when none is set.
+func (u *User) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Age is the user's age in whole years at now, or 0 when their date of
+// birth is not set.
+func (u *User) Age(now time.Time) int {
+	if u.DateOfBirth.IsZero() {
+		return 0
+	}
+	years := now.Year() - u.DateOfBirth.Year()
+	if now.Month() < u.DateOfBirth.Month() || (now.Month() == u.DateOfBirth.Month() && now.Day() < u.DateOfBirth.Day()) {
+		years--
+	}
+	return years
 }
 
 type UserSettings struct {
@@ -29,20 +63,36 @@ type UserSettings struct {
 	ActivityReminders  bool `firestore:"activityReminders" json:"activityReminders"`
 	MedicationAlerts   bool `firestore:"medicationAlerts" json:"medicationAlerts"`
 	TwoFactorAuth      bool `firestore:"twoFactorAuth" json:"twoFactorAuth"`
+	// PersonalizedAdvice lets chat answers take the user's profile and
+	// recent activity into account. Off unless the user opts in.
+	PersonalizedAdvice bool `firestore:"personalizedAdvice" json:"personalizedAdvice"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	Code           string `json:"code" binding:"required"` // TOTP or recovery code
+	DeviceID       string `json:"deviceId"`
 }
 
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
+	DeviceID string `json:"deviceId"`
 }
 
 type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
 	FullName string `json:"fullName" binding:"required"`
+	DeviceID string `json:"deviceId"`
 }
 
 type GoogleAuthRequest struct {
 	Token    string `json:"token" binding:"required"`
 	FullName string `json:"fullName,omitempty"`
+	DeviceID string `json:"deviceId"`
 }
@@ -0,0 +1,249 @@
+// Package rag is the client for the RAG service that answers chat messages.
+package rag
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"orchestrator-service/models"
+)
+
+var (
+	// ErrCircuitOpen is returned without calling the service while it is
+	// considered down.
+	ErrCircuitOpen = errors.New("rag service unavailable")
+	// ErrBadResponse means the service answered with something unusable.
+	ErrBadResponse = errors.New("invalid response from rag service")
+)
+
+// CircuitOpenError carries how long callers should wait before retrying.
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string { return ErrCircuitOpen.Error() }
+func (e *CircuitOpenError) Unwrap() error { return ErrCircuitOpen }
+
+type Request struct {
+	Query   string                   `json:"query"`
+	History []models.ChatHistoryItem `json:"history"`
+	Context *models.HealthContext    `json:"context,omitempty"`
+}
+
+type Client struct {
+	cfg     Config
+	http    *http.Client
+	breaker *breaker
+}
+
+func NewClient(cfg Config) *Client {
+	return &Client{
+		cfg:     cfg,
+		http:    &http.Client{},
+		breaker: &breaker{threshold: cfg.BreakerThreshold, cooldown: cfg.BreakerCooldown},
+	}
+}
+
+// HistoryBudget is the configured limit on conversation history.
+func (c *Client) HistoryBudget() HistoryBudget {
+	return c.cfg.History
+}
+
+// Query asks the RAG service for a complete answer.
+func (c *Client) Query(ctx context.Context, req Request) (string, error) {
+	var answer string
+	err := c.do(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+		defer cancel()
+
+		resp, err := c.post(ctx, "/query", req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		var ragResponse struct {
+			Answer string `json:"answer"`
+		}
+		if err := json.Unmarshal(body, &ragResponse); err != nil || ragResponse.Answer == "" {
+			return permanent(fmt.Errorf("%w: %s", ErrBadResponse, truncate(body, 200)))
+		}
+
+		answer = ragResponse.Answer
+		return nil
+	})
+	return answer, err
+}
+
+// Stream asks for an answer from the streaming endpoint, passing each chunk
+// to onToken as it arrives, and returns the full answer. Only the
+// connection is retried; once chunks have been delivered a failure is final.
+func (c *Client) Stream(ctx context.Context, req Request, onToken func(string)) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.StreamTimeout)
+	defer cancel()
+
+	var resp *http.Response
+	err := c.do(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = c.post(ctx, "/query/stream", req)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	answer, err := readStream(resp.Body, onToken)
+	if err != nil && ctx.Err() == nil {
+		c.breaker.failure()
+	}
+	return answer, err
+}
+
+// do runs attempt through the circuit breaker, retrying transient failures
+// with exponential backoff.
+func (c *Client) do(ctx context.Context, attempt func(ctx context.Context) error) error {
+	backoff := c.cfg.InitialBackoff
+
+	for i := 0; ; i++ {
+		if ok, retryAfter := c.breaker.allow(); !ok {
+			return &CircuitOpenError{RetryAfter: retryAfter}
+		}
+
+		err := attempt(ctx)
+		if err == nil {
+			c.breaker.success()
+			return nil
+		}
+
+		// The caller gave up; that says nothing about the service
+		if ctx.Err() != nil {
+			c.breaker.abort()
+			return ctx.Err()
+		}
+
+		// The service answered, so it is up; retrying won't help either
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			c.breaker.success()
+			return perm.err
+		}
+
+		c.breaker.failure()
+		if i >= c.cfg.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+		if backoff > c.cfg.MaxBackoff {
+			backoff = c.cfg.MaxBackoff
+		}
+	}
+}
+
+// post sends req as JSON. Non-2xx answers are turned into errors: 5xx and
+// 429 are retryable, anything else is not.
+func (c *Client) post(ctx context.Context, path string, req Request) (*http.Response, error) {
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return nil, permanent(err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.BaseURL+path, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, permanent(err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+
+		err := fmt.Errorf("rag service returned %s: %s", resp.Status, truncate(body, 200))
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			return nil, err
+		}
+		return nil, permanent(err)
+	}
+
+	return resp, nil
+}
+
+// readStream consumes the newline-delimited JSON events of /query/stream.
+func readStream(body io.Reader, onToken func(string)) (string, error) {
+	var answer strings.Builder
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var event struct {
+			Token string `json:"token"`
+			Done  bool   `json:"done"`
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(line, &event); err != nil {
+			return "", fmt.Errorf("%w: %v", ErrBadResponse, err)
+		}
+
+		switch {
+		case event.Error != "":
+			return "", fmt.Errorf("rag service error: %s", event.Error)
+		case event.Done:
+			return answer.String(), nil
+		case event.Token != "":
+			answer.WriteString(event.Token)
+			onToken(event.Token)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", fmt.Errorf("%w: stream ended before completion", ErrBadResponse)
+}
+
+// permanentError marks a failure that retrying will not fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+func truncate(b []byte, n int) string {
+	if len(b) > n {
+		return string(b[:n]) + "..."
+	}
+	return string(b)
+}
@@ -0,0 +1,108 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Modes of the fake RAG service
+const (
+	modeFail int32 = iota
+	modeHang
+	modeOK
+)
+
+func fakeService(t *testing.T, mode *atomic.Int32) *httptest.Server {
+	t.Helper()
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch mode.Load() {
+		case modeFail:
+			http.Error(w, "down", http.StatusInternalServerError)
+		case modeHang:
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"answer":"ok"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) }) // Runs first, freeing hung handlers
+	return srv
+}
+
+func testClient(baseURL string) *Client {
+	cfg := DefaultConfig()
+	cfg.BaseURL = baseURL
+	cfg.MaxRetries = 0
+	cfg.BreakerThreshold = 1
+	cfg.BreakerCooldown = 20 * time.Millisecond
+	return NewClient(cfg)
+}
+
+func TestCancelledTrialReleasesBreaker(t *testing.T) {
+	var mode atomic.Int32
+	srv := fakeService(t, &mode)
+	client := testClient(srv.URL)
+
+	// Open the circuit
+	mode.Store(modeFail)
+	if _, err := client.Query(context.Background(), Request{Query: "hi"}); err == nil {
+		t.Fatal("expected the failing service to return an error")
+	}
+	if _, err := client.Query(context.Background(), Request{Query: "hi"}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the circuit to be open, got %v", err)
+	}
+
+	// The trial call after the cooldown is abandoned by its caller
+	time.Sleep(2 * client.cfg.BreakerCooldown)
+	mode.Store(modeHang)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.Query(ctx, Request{Query: "hi"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the trial call to time out, got %v", err)
+	}
+
+	// The service has recovered; the next call must be let through
+	mode.Store(modeOK)
+	answer, err := client.Query(context.Background(), Request{Query: "hi"})
+	if err != nil {
+		t.Fatalf("expected the call after an abandoned trial to be allowed, got %v", err)
+	}
+	if answer != "ok" {
+		t.Fatalf("answer = %q, want %q", answer, "ok")
+	}
+}
+
+func TestBreakerAbortKeepsState(t *testing.T) {
+	b := &breaker{threshold: 1, cooldown: time.Hour}
+	b.failure()
+
+	if ok, _ := b.allow(); ok {
+		t.Fatal("expected the open circuit to refuse calls during the cooldown")
+	}
+
+	b.openUntil = time.Now().Add(-time.Second)
+	if ok, _ := b.allow(); !ok {
+		t.Fatal("expected a trial call after the cooldown")
+	}
+	if ok, _ := b.allow(); ok {
+		t.Fatal("expected a second call to wait for the trial")
+	}
+
+	b.abort()
+	if b.failures != 1 {
+		t.Fatalf("abort changed failures to %d", b.failures)
+	}
+	if ok, _ := b.allow(); !ok {
+		t.Fatal("expected a new trial call after the previous one was aborted")
+	}
+}
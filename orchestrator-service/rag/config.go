@@ -0,0 +1,67 @@
+package rag
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	// BaseURL of the RAG service, without the endpoint path.
+	BaseURL string
+	// Timeout bounds a single /query attempt.
+	Timeout time.Duration
+	// StreamTimeout bounds a whole streamed answer.
+	StreamTimeout time.Duration
+	// MaxRetries is how many times a failed call is retried.
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// After BreakerThreshold consecutive failures calls fail fast for
+	// BreakerCooldown before a single trial call is let through.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// History limits the conversation history sent with each query.
+	History HistoryBudget
+}
+
+func DefaultConfig() Config {
+	return Config{
+		BaseURL:          "http://rag-service:8000",
+		Timeout:          60 * time.Second,
+		StreamTimeout:    2 * time.Minute,
+		MaxRetries:       2,
+		InitialBackoff:   500 * time.Millisecond,
+		MaxBackoff:       5 * time.Second,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+		History:          DefaultHistoryBudget(),
+	}
+}
+
+// ConfigFromEnv starts from DefaultConfig and applies RAG_URL, RAG_TIMEOUT
+// (a duration such as "45s"), RAG_MAX_RETRIES, RAG_HISTORY_TURNS and
+// RAG_HISTORY_TOKENS.
+func ConfigFromEnv() Config {
+	cfg := DefaultConfig()
+
+	if url := os.Getenv("RAG_URL"); url != "" {
+		// Accept the full query URL as well as the base URL
+		cfg.BaseURL = strings.TrimSuffix(strings.TrimSuffix(url, "/"), "/query")
+	}
+	if timeout, err := time.ParseDuration(os.Getenv("RAG_TIMEOUT")); err == nil && timeout > 0 {
+		cfg.Timeout = timeout
+	}
+	if retries, err := strconv.Atoi(os.Getenv("RAG_MAX_RETRIES")); err == nil && retries >= 0 {
+		cfg.MaxRetries = retries
+	}
+	if turns, err := strconv.Atoi(os.Getenv("RAG_HISTORY_TURNS")); err == nil && turns >= 0 {
+		cfg.History.MaxTurns = turns
+	}
+	if tokens, err := strconv.Atoi(os.Getenv("RAG_HISTORY_TOKENS")); err == nil && tokens >= 0 {
+		cfg.History.MaxTokens = tokens
+	}
+
+	return cfg
+}
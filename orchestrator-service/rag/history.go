@@ -0,0 +1,93 @@
+package rag
+
+import (
+	"unicode/utf8"
+
+	"orchestrator-service/models"
+)
+
+// HistoryBudget bounds the conversation history sent along with a query.
+// Older messages are dropped first; the newest ones are always preferred.
+type HistoryBudget struct {
+	// MaxTurns is how many user/AI exchanges are kept.
+	MaxTurns int
+	// MaxTokens caps the estimated size of the whole history.
+	MaxTokens int
+	// MaxMessageTokens clips single messages longer than this instead of
+	// letting one long answer push out all earlier context. Zero disables
+	// clipping.
+	MaxMessageTokens int
+}
+
+func DefaultHistoryBudget() HistoryBudget {
+	return HistoryBudget{
+		MaxTurns:         10,
+		MaxTokens:        2000,
+		MaxMessageTokens: 500,
+	}
+}
+
+// MaxMessages is the most stored messages Apply can use, so callers need
+// not load more.
+func (b HistoryBudget) MaxMessages() int {
+	return b.MaxTurns * 2
+}
+
+// Apply turns stored messages, oldest first, into the history for a query.
+// It walks back from the newest message until the turn or token budget is
+// spent, then drops a leading AI message so the history opens with the
+// user.
+func (b HistoryBudget) Apply(messages []models.ChatMessage) []models.ChatHistoryItem {
+	if len(messages) > b.MaxMessages() {
+		messages = messages[len(messages)-b.MaxMessages():]
+	}
+
+	var (
+		kept   []models.ChatHistoryItem
+		tokens int
+	)
+	for i := len(messages) - 1; i >= 0; i-- {
+		text := messages[i].Text
+		if b.MaxMessageTokens > 0 && estimateTokens(text) > b.MaxMessageTokens {
+			text = clip(text, b.MaxMessageTokens)
+		}
+
+		cost := estimateTokens(text)
+		if tokens+cost > b.MaxTokens {
+			break
+		}
+		tokens += cost
+
+		kept = append(kept, models.ChatHistoryItem{
+			Sender:    messages[i].Sender,
+			Text:      text,
+			Timestamp: messages[i].Timestamp,
+		})
+	}
+
+	// Restore chronological order
+	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+		kept[i], kept[j] = kept[j], kept[i]
+	}
+
+	for len(kept) > 0 && kept[0].Sender != "user" {
+		kept = kept[1:]
+	}
+
+	return kept
+}
+
+// estimateTokens approximates a tokenizer at four characters per token,
+// plus a little overhead for the message framing.
+func estimateTokens(text string) int {
+	return (utf8.RuneCountInString(text)+3)/4 + 4
+}
+
+// clip cuts text to roughly the given number of tokens.
+func clip(text string, tokens int) string {
+	runes := []rune(text)
+	if limit := (tokens - 4) * 4; limit > 0 && len(runes) > limit {
+		return string(runes[:limit]) + "…"
+	}
+	return text
+}
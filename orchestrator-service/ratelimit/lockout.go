@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LockoutPolicy locks a key out after Threshold failures within Window. The
+// lock starts at BaseDelay and doubles with every further failure, up to
+// MaxDelay.
+type LockoutPolicy struct {
+	Threshold int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	Window    time.Duration
+}
+
+type Lockout struct {
+	store  Store
+	policy LockoutPolicy
+	name   string
+}
+
+func NewLockout(store Store, name string, policy LockoutPolicy) *Lockout {
+	return &Lockout{store: store, policy: policy, name: name}
+}
+
+// Middleware rejects locked-out keys up front, then watches the handler's
+// answer: a 401 counts as a failed attempt and a 2xx clears the record.
+func (l *Lockout) Middleware(keyFunc KeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := keyFunc(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+		key = l.name + ":" + key
+		ctx := c.Request.Context()
+
+		entry, err := l.store.Get(ctx, key)
+		if err != nil {
+			log.Printf("Lockout store error: %v", err)
+		} else if retryAfter := l.retryAfter(entry); retryAfter > 0 {
+			tooManyRequests(c, retryAfter)
+			return
+		}
+
+		c.Next()
+
+		switch status := c.Writer.Status(); {
+		case status == http.StatusUnauthorized:
+			if _, err := l.store.Increment(ctx, key, l.policy.Window); err != nil {
+				log.Printf("Lockout store error: %v", err)
+			}
+		case status >= 200 && status < 300:
+			if err := l.store.Delete(ctx, key); err != nil {
+				log.Printf("Lockout store error: %v", err)
+			}
+		}
+	}
+}
+
+// retryAfter is how long the key stays locked, or zero if it is not.
+func (l *Lockout) retryAfter(entry Entry) time.Duration {
+	if entry.Count < l.policy.Threshold {
+		return 0
+	}
+
+	delay := l.policy.BaseDelay
+	for i := l.policy.Threshold; i < entry.Count && delay < l.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > l.policy.MaxDelay {
+		delay = l.policy.MaxDelay
+	}
+
+	return time.Until(entry.LastHit.Add(delay))
+}
@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KeyFunc extracts what a limit applies to from the request. An empty key
+// exempts the request from that limit.
+type KeyFunc func(c *gin.Context) string
+
+// Rule allows Limit requests per Window for each key.
+type Rule struct {
+	Name   string // Namespaces the keys of this rule in the store
+	Limit  int
+	Window time.Duration
+	Key    KeyFunc
+}
+
+// Limit enforces a fixed-window rule, answering 429 with Retry-After once
+// a key runs over.
+func Limit(store Store, rule Rule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := rule.Key(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		entry, err := store.Increment(c.Request.Context(), rule.Name+":"+key, rule.Window)
+		if err != nil {
+			// Fail open: an unavailable store should not lock everyone out
+			log.Printf("Rate limit store error: %v", err)
+			c.Next()
+			return
+		}
+
+		if entry.Count > rule.Limit {
+			tooManyRequests(c, time.Until(entry.ExpiresAt))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ClientIP keys a rule by the caller's IP address.
+func ClientIP(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// JSONField keys a rule by a string field of the JSON request body, such as
+// the email of a login attempt. The body is restored for the handler.
+func JSONField(field string) KeyFunc {
+	return func(c *gin.Context) string {
+		return strings.ToLower(strings.TrimSpace(JSONValue(c, field)))
+	}
+}
+
+// JSONValue reads a string field of the JSON request body as sent, for key
+// functions that derive their key from it. The body is restored for the
+// handler.
+func JSONValue(c *gin.Context, field string) string {
+	if c.Request.Body == nil {
+		return ""
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+	c.Request.Body.Close()
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return ""
+	}
+
+	value, _ := fields[field].(string)
+	return value
+}
+
+func tooManyRequests(c *gin.Context, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+		"error":      "Too many requests, please try again later",
+		"retryAfter": seconds,
+	})
+}
@@ -0,0 +1,90 @@
+// Package ratelimit provides Gin middleware for request throttling and
+// progressive lockout after failed sign-in attempts.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Entry is a counter for one key.
+type Entry struct {
+	Count     int
+	LastHit   time.Time
+	ExpiresAt time.Time // End of the window the count belongs to
+}
+
+// Store keeps counters. MemoryStore suits a single instance; running several
+// replicas behind a load balancer needs a shared implementation (e.g. Redis
+// or the database) so limits apply across them.
+type Store interface {
+	// Increment adds a hit to key. A key that does not exist or has expired
+	// starts a new window of length ttl.
+	Increment(ctx context.Context, key string, ttl time.Duration) (Entry, error)
+	// Get returns the current entry; a missing or expired key is the zero Entry.
+	Get(ctx context.Context, key string) (Entry, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]Entry
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]Entry)}
+}
+
+func (s *MemoryStore) Increment(ctx context.Context, key string, ttl time.Duration) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	entry, ok := s.entries[key]
+	if !ok || !now.Before(entry.ExpiresAt) {
+		entry = Entry{ExpiresAt: now.Add(ttl)}
+	}
+	entry.Count++
+	entry.LastHit = now
+
+	s.entries[key] = entry
+	return entry, nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || !time.Now().Before(entry.ExpiresAt) {
+		return Entry{}, nil
+	}
+	return entry, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// sweep drops expired entries at most once a minute so the map does not
+// grow with every IP ever seen.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, entry := range s.entries {
+		if !now.Before(entry.ExpiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
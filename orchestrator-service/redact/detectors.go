@@ -0,0 +1,167 @@
+package redact
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Detector finds one kind of personal information in a text.
+type Detector interface {
+	// Kind names the placeholder, e.g. "EMAIL" for [EMAIL_1].
+	Kind() string
+	// Find returns the [start, end) byte offsets of every match.
+	Find(text string) [][2]int
+}
+
+// DefaultDetectors are the detectors that need no knowledge of the user.
+func DefaultDetectors() []Detector {
+	return []Detector{Email(), Date(), Phone()}
+}
+
+type patternDetector struct {
+	kind    string
+	pattern *regexp.Regexp
+	// valid, if set, filters out false positives.
+	valid func(match string) bool
+}
+
+func (d *patternDetector) Kind() string { return d.kind }
+
+func (d *patternDetector) Find(text string) [][2]int {
+	var spans [][2]int
+	for _, m := range d.pattern.FindAllStringIndex(text, -1) {
+		if d.valid != nil && !d.valid(text[m[0]:m[1]]) {
+			continue
+		}
+		spans = append(spans, [2]int{m[0], m[1]})
+	}
+	return spans
+}
+
+var emailPattern = regexp.MustCompile(`(?i)\b[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}\b`)
+
+// Email detects email addresses.
+func Email() Detector {
+	return &patternDetector{kind: "EMAIL", pattern: emailPattern}
+}
+
+// A loose shape for phone numbers, narrowed down by validPhone.
+var phonePattern = regexp.MustCompile(`(?:\+\(?|\(|\b)\d[\d\s().-]{5,}\d\b`)
+
+// Phone detects phone numbers, local or international.
+func Phone() Detector {
+	return &patternDetector{kind: "PHONE", pattern: phonePattern, valid: validPhone}
+}
+
+// decimalPattern is a number with a fractional part, such as a measurement.
+var decimalPattern = regexp.MustCompile(`^\d+\.\d+$`)
+
+// validPhone accepts 9 to 15 digits. Unformatted numbers need at least 10
+// so that plain quantities such as "150000000 steps" are less likely to
+// match, and decimals such as "1234567.89" are never taken for numbers.
+func validPhone(match string) bool {
+	if decimalPattern.MatchString(match) {
+		return false
+	}
+
+	digits := 0
+	for _, r := range match {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+	}
+	if digits < 9 || digits > 15 {
+		return false
+	}
+
+	formatted := strings.HasPrefix(match, "+") || strings.ContainsAny(match, " ().-")
+	return formatted || digits >= 10
+}
+
+const month = `(?:Jan(?:uary)?|Feb(?:ruary)?|Mar(?:ch)?|Apr(?:il)?|May|June?|July?|Aug(?:ust)?|Sep(?:t(?:ember)?)?|Oct(?:ober)?|Nov(?:ember)?|Dec(?:ember)?)\.?`
+
+var datePattern = regexp.MustCompile(strings.Join([]string{
+	// 2024-03-05
+	`\b\d{4}-\d{1,2}-\d{1,2}\b`,
+	// 05/03/2024, 5.3.24
+	`\b\d{1,2}[/.]\d{1,2}[/.](?:\d{4}|\d{2})\b`,
+	// 5 March 2024, 5th of March
+	`\b\d{1,2}(?:st|nd|rd|th)?\s+(?:of\s+)?` + month + `(?:,?\s+\d{4})?\b`,
+	// March 5th, 2024
+	`\b` + month + `\s+\d{1,2}(?:st|nd|rd|th)?(?:,?\s+\d{4})?\b`,
+	// March 2024
+	`\b` + month + `\s+\d{4}\b`,
+}, "|"))
+
+// Date detects calendar dates. Month names must be capitalized so that
+// words like "may" and "march" are left alone.
+func Date() Detector {
+	return &patternDetector{kind: "DATE", pattern: datePattern}
+}
+
+var introductionPattern = regexp.MustCompile(`\b(?:[Mm]y name is|[Cc]all me|[Mm]y (?:wife|husband|son|daughter|partner|doctor) is)\s+((?:Dr\.?\s+)?\p{Lu}\p{Ll}+(?:\s+\p{Lu}\p{Ll}+)?)`)
+
+// Names detects the given names, typically the user's own, as whole words,
+// along with names the user introduces ("my name is ..."). A full name
+// matches in any case, but a single part only when capitalized, since names
+// such as Will, Grace or Hope are ordinary words too.
+func Names(names ...string) Detector {
+	var full, parts []string
+	for _, name := range names {
+		fields := strings.Fields(name)
+		if len(fields) > 1 {
+			full = append(full, strings.Join(fields, " "))
+		}
+		// Initials and very short names would match ordinary words
+		for _, part := range fields {
+			if len([]rune(part)) > 2 {
+				parts = append(parts, capitalize(part))
+			}
+		}
+	}
+
+	return &nameDetector{known: knownNamesPattern(full, parts)}
+}
+
+func capitalize(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+	return string(unicode.ToUpper(r)) + word[size:]
+}
+
+type nameDetector struct {
+	known *regexp.Regexp
+}
+
+func (d *nameDetector) Kind() string { return "NAME" }
+
+func (d *nameDetector) Find(text string) [][2]int {
+	var spans [][2]int
+	if d.known != nil {
+		for _, m := range d.known.FindAllStringIndex(text, -1) {
+			spans = append(spans, [2]int{m[0], m[1]})
+		}
+	}
+	for _, m := range introductionPattern.FindAllStringSubmatchIndex(text, -1) {
+		spans = append(spans, [2]int{m[2], m[3]})
+	}
+	return spans
+}
+
+// knownNamesPattern matches any of the full names in any case, or any of
+// the parts exactly. Full names come first, and earlier alternatives win,
+// so "Jane Doe" is one match rather than two.
+func knownNamesPattern(full, parts []string) *regexp.Regexp {
+	var alternatives []string
+	for _, name := range full {
+		alternatives = append(alternatives, `(?i:`+regexp.QuoteMeta(name)+`)`)
+	}
+	for _, part := range parts {
+		alternatives = append(alternatives, regexp.QuoteMeta(part))
+	}
+	if len(alternatives) == 0 {
+		return nil
+	}
+	return regexp.MustCompile(`\b(?:` + strings.Join(alternatives, "|") + `)\b`)
+}
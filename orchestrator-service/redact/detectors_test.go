@@ -0,0 +1,171 @@
+package redact
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// found returns the text of every match of d in text.
+func found(d Detector, text string) []string {
+	var matches []string
+	for _, span := range d.Find(text) {
+		matches = append(matches, text[span[0]:span[1]])
+	}
+	return matches
+}
+
+type detectorCase struct {
+	text string
+	want []string
+}
+
+func runDetectorCases(t *testing.T, d Detector, cases []detectorCase) {
+	t.Helper()
+	for _, tc := range cases {
+		if got := found(d, tc.text); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s in %q = %q, want %q", d.Kind(), tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestEmail(t *testing.T) {
+	runDetectorCases(t, Email(), []detectorCase{
+		{"write to jane.doe@example.com please", []string{"jane.doe@example.com"}},
+		{"Jane+health@Mail.Example.co.uk", []string{"Jane+health@Mail.Example.co.uk"}},
+		{"a@b.co and c_d@e.org", []string{"a@b.co", "c_d@e.org"}},
+		{"no address @ here", nil},
+		{"user@localhost", nil},
+	})
+}
+
+func TestPhone(t *testing.T) {
+	runDetectorCases(t, Phone(), []detectorCase{
+		{"call +40 721 234 567 today", []string{"+40 721 234 567"}},
+		{"my number is (555) 123-4567", []string{"(555) 123-4567"}},
+		{"0721234567", []string{"0721234567"}},
+		{"555.123.4567", []string{"555.123.4567"}},
+		{"+447911123456", []string{"+447911123456"}},
+
+		// Quantities and measurements
+		{"I walked 150000000 steps", nil},
+		{"12345 steps", nil},
+		{"weight 72.5 kg", nil},
+		{"total 1234567.89", nil},
+		{"pi is 3.14159265", nil},
+		{"1234567890123456789", nil}, // Too many digits
+	})
+}
+
+func TestDate(t *testing.T) {
+	runDetectorCases(t, Date(), []detectorCase{
+		{"born 1990-05-01", []string{"1990-05-01"}},
+		{"on 05/03/2024", []string{"05/03/2024"}},
+		{"on 5.3.24", []string{"5.3.24"}},
+		{"since 5 March 2024", []string{"5 March 2024"}},
+		{"the 5th of March", []string{"5th of March"}},
+		{"March 5th, 2024", []string{"March 5th, 2024"}},
+		{"in Sept. 2023", []string{"Sept. 2023"}},
+		{"May 12", []string{"May 12"}},
+
+		// Month names used as ordinary words
+		{"I may 5 times a day feel dizzy", nil},
+		{"you may need 2 hours", nil},
+		{"we march 10 miles", nil},
+		{"in May I felt better", nil},
+	})
+}
+
+func TestNames(t *testing.T) {
+	runDetectorCases(t, Names("Jane Doe"), []detectorCase{
+		{"Jane Doe here", []string{"Jane Doe"}},
+		{"jane doe here", []string{"jane doe"}},
+		{"ask Jane, or Doe", []string{"Jane", "Doe"}},
+		{"ask jane, or doe", nil},
+		{"Janet Doering", nil},
+	})
+
+	// Names that are also ordinary words
+	runDetectorCases(t, Names("will grace"), []detectorCase{
+		{"I will run with grace", nil},
+		{"Will Grace here", []string{"Will Grace"}},
+		{"will grace here", []string{"will grace"}},
+		{"ask Will about it", []string{"Will"}},
+		{"saying grace", nil},
+	})
+
+	// Short parts would match ordinary words
+	runDetectorCases(t, Names("Jo Li"), []detectorCase{
+		{"Jo Li", []string{"Jo Li"}},
+		{"jo went to li", nil},
+	})
+
+	runDetectorCases(t, Names(), []detectorCase{
+		{"My name is Alice Smith.", []string{"Alice Smith"}},
+		{"call me Bob", []string{"Bob"}},
+		{"my doctor is Dr. House", []string{"Dr. House"}},
+		{"my name is not important", nil},
+	})
+}
+
+func TestRedactRestoreRoundTrip(t *testing.T) {
+	r := New(append(DefaultDetectors(), Names("Jane Doe"))...)
+
+	text := "I'm Jane Doe, email jane@example.com or call +40 721 234 567. " +
+		"Since 2024-03-05 I write to jane@example.com daily."
+	redacted := r.Redact(text)
+
+	for _, value := range []string{"Jane Doe", "jane@example.com", "+40 721 234 567", "2024-03-05"} {
+		if strings.Contains(redacted, value) {
+			t.Errorf("redacted text still contains %q: %s", value, redacted)
+		}
+	}
+	if n := strings.Count(redacted, "[EMAIL_1]"); n != 2 {
+		t.Errorf("the same address should get the same placeholder twice, got %d in %s", n, redacted)
+	}
+	if r.Count() != 4 {
+		t.Errorf("Count() = %d, want 4", r.Count())
+	}
+
+	if restored := r.Restore(redacted); restored != text {
+		t.Errorf("Restore(Redact(text)) = %q, want %q", restored, text)
+	}
+
+	// Placeholders this Redactor did not issue are left alone
+	if got := r.Restore("[EMAIL_9] and [NAME_1]"); got != "[EMAIL_9] and Jane Doe" {
+		t.Errorf("Restore left %q", got)
+	}
+}
+
+func TestStreamRestorerSplitPlaceholder(t *testing.T) {
+	r := New(Names("Jane Doe"))
+	redacted := r.Redact("Hello Jane Doe, how are you?")
+	if redacted != "Hello [NAME_1], how are you?" {
+		t.Fatalf("unexpected redaction %q", redacted)
+	}
+
+	s := r.NewStreamRestorer()
+	var out []string
+	for _, chunk := range []string{"Hello [NA", "ME_1], how", " are you?"} {
+		out = append(out, s.Write(chunk))
+	}
+	out = append(out, s.Flush())
+
+	want := []string{"Hello ", "Jane Doe, how", " are you?", ""}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("stream output = %q, want %q", out, want)
+	}
+}
+
+func TestStreamRestorerFlushesUnfinishedBracket(t *testing.T) {
+	r := New(Names("Jane Doe"))
+	r.Redact("Jane Doe")
+
+	s := r.NewStreamRestorer()
+	if got := s.Write("see note [1"); got != "see note " {
+		t.Errorf("Write = %q, want the bracket held back", got)
+	}
+	if got := s.Flush(); got != "[1" {
+		t.Errorf("Flush = %q, want %q", got, "[1")
+	}
+}
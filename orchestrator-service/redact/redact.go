@@ -0,0 +1,145 @@
+// Package redact replaces personal information in chat text with
+// placeholders before it leaves the service, and puts the original values
+// back into the answer.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxPlaceholderLength bounds how much of a streamed answer is held back
+// while waiting for a placeholder to complete.
+const maxPlaceholderLength = 16
+
+var placeholderPattern = regexp.MustCompile(`\[([A-Z]+)_(\d+)\]`)
+
+// Redactor replaces matches with numbered placeholders such as [EMAIL_1].
+// One Redactor is used per outgoing request: the same value always gets
+// the same placeholder, across the message and its history, so the model
+// can still tell that two mentions refer to the same thing.
+type Redactor struct {
+	detectors []Detector
+
+	placeholders map[string]string // value -> placeholder
+	values       map[string]string // placeholder -> value
+	counts       map[string]int    // kind -> placeholders issued
+}
+
+func New(detectors ...Detector) *Redactor {
+	return &Redactor{
+		detectors:    detectors,
+		placeholders: make(map[string]string),
+		values:       make(map[string]string),
+		counts:       make(map[string]int),
+	}
+}
+
+type match struct {
+	start, end int
+	kind       string
+}
+
+// Redact returns text with every detected value replaced. Where matches
+// overlap, the earlier detector wins, then the longer match.
+func (r *Redactor) Redact(text string) string {
+	var matches []match
+	for _, detector := range r.detectors {
+		for _, span := range detector.Find(text) {
+			matches = append(matches, match{span[0], span[1], detector.Kind()})
+		}
+	}
+	if len(matches) == 0 {
+		return text
+	}
+
+	// Stable, so detector order breaks ties
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].start != matches[j].start {
+			return matches[i].start < matches[j].start
+		}
+		return matches[i].end > matches[j].end
+	})
+
+	var (
+		b    strings.Builder
+		last int
+	)
+	for _, m := range matches {
+		if m.start < last {
+			continue // Overlaps a match already replaced
+		}
+		b.WriteString(text[last:m.start])
+		b.WriteString(r.placeholder(m.kind, text[m.start:m.end]))
+		last = m.end
+	}
+	b.WriteString(text[last:])
+
+	return b.String()
+}
+
+func (r *Redactor) placeholder(kind, value string) string {
+	key := kind + "\x00" + value
+	if placeholder, ok := r.placeholders[key]; ok {
+		return placeholder
+	}
+
+	r.counts[kind]++
+	placeholder := fmt.Sprintf("[%s_%d]", kind, r.counts[kind])
+	r.placeholders[key] = placeholder
+	r.values[placeholder] = value
+	return placeholder
+}
+
+// Restore puts the original values back in place of the placeholders this
+// Redactor issued. Anything else that looks like a placeholder, such as
+// one the model made up, is left as it is.
+func (r *Redactor) Restore(text string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		if value, ok := r.values[placeholder]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// Count is the number of distinct values redacted so far.
+func (r *Redactor) Count() int {
+	return len(r.values)
+}
+
+// StreamRestorer restores placeholders in an answer that arrives in
+// chunks, where a placeholder may be split across two of them.
+type StreamRestorer struct {
+	redactor *Redactor
+	pending  string
+}
+
+func (r *Redactor) NewStreamRestorer() *StreamRestorer {
+	return &StreamRestorer{redactor: r}
+}
+
+// Write takes the next chunk and returns the text that is ready to be
+// shown, holding back a trailing fragment that could still turn out to be
+// a placeholder.
+func (s *StreamRestorer) Write(chunk string) string {
+	text := s.pending + chunk
+	s.pending = ""
+
+	if open := strings.LastIndexByte(text, '['); open >= 0 &&
+		!strings.Contains(text[open:], "]") && len(text)-open < maxPlaceholderLength {
+		s.pending = text[open:]
+		text = text[:open]
+	}
+
+	return s.redactor.Restore(text)
+}
+
+// Flush returns whatever is still held back once the answer is complete.
+func (s *StreamRestorer) Flush() string {
+	text := s.redactor.Restore(s.pending)
+	s.pending = ""
+	return text
+}
@@ -0,0 +1,52 @@
+package firestorerepo
+
+import (
+	"context"
+
+	"orchestrator-service/models"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// achievementRepository keys documents by user and badge, so a badge can
+// only be earned once.
+type achievementRepository struct {
+	client *firestore.Client
+}
+
+func (r *achievementRepository) Award(ctx context.Context, achievement *models.Achievement) error {
+	_, err := r.client.Collection("achievements").Doc(achievement.UserID+"_"+achievement.BadgeID).Create(ctx, achievement)
+	if status.Code(err) == codes.AlreadyExists {
+		return nil
+	}
+	return err
+}
+
+func (r *achievementRepository) List(ctx context.Context, userID string) ([]models.Achievement, error) {
+	iter := r.client.Collection("achievements").
+		Where("userId", "==", userID).
+		OrderBy("earnedAt", firestore.Asc).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var achievements []models.Achievement
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var achievement models.Achievement
+		if err := doc.DataTo(&achievement); err != nil {
+			return nil, err
+		}
+		achievements = append(achievements, achievement)
+	}
+	return achievements, nil
+}
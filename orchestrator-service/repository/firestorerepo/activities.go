@@ -0,0 +1,83 @@
+package firestorerepo
+
+import (
+	"context"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+type activityRepository struct {
+	client *firestore.Client
+}
+
+func (r *activityRepository) Create(ctx context.Context, activity *models.Activity) error {
+	_, err := r.client.Collection("activities").Doc(activity.ID).Set(ctx, activity)
+	return err
+}
+
+func (r *activityRepository) Get(ctx context.Context, id string) (*models.Activity, error) {
+	doc, err := r.client.Collection("activities").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var activity models.Activity
+	if err := doc.DataTo(&activity); err != nil {
+		return nil, err
+	}
+	return &activity, nil
+}
+
+func (r *activityRepository) Update(ctx context.Context, activity *models.Activity) error {
+	_, err := r.client.Collection("activities").Doc(activity.ID).Update(ctx, []firestore.Update{
+		{Path: "type", Value: activity.Type},
+		{Path: "value", Value: activity.Value},
+		{Path: "unit", Value: activity.Unit},
+		{Path: "description", Value: activity.Description},
+		{Path: "date", Value: activity.Date},
+	})
+	return translateError(err)
+}
+
+func (r *activityRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.client.Collection("activities").Doc(id).Delete(ctx)
+	return err
+}
+
+func (r *activityRepository) List(ctx context.Context, filter repository.ActivityFilter) ([]models.Activity, error) {
+	query := r.client.Collection("activities").Where("userId", "==", filter.UserID)
+	if filter.Type != "" {
+		query = query.Where("type", "==", filter.Type)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("date", ">=", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("date", "<", filter.To)
+	}
+
+	iter := page(query, "date", firestore.Asc, filter.After, filter.Limit).Documents(ctx)
+	defer iter.Stop()
+
+	var activities []models.Activity
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var activity models.Activity
+		if err := doc.DataTo(&activity); err != nil {
+			return nil, err
+		}
+		activities = append(activities, activity)
+	}
+	return activities, nil
+}
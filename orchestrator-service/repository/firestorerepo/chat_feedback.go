@@ -0,0 +1,106 @@
+package firestorerepo
+
+import (
+	"context"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// chatFeedbackRepository keys documents by message ID, which keeps one
+// feedback entry per message.
+type chatFeedbackRepository struct {
+	client *firestore.Client
+}
+
+func (r *chatFeedbackRepository) Save(ctx context.Context, feedback *models.ChatFeedback) error {
+	_, err := r.client.Collection("chat_feedback").Doc(feedback.MessageID).Set(ctx, feedback)
+	return err
+}
+
+func (r *chatFeedbackRepository) Get(ctx context.Context, messageID string) (*models.ChatFeedback, error) {
+	doc, err := r.client.Collection("chat_feedback").Doc(messageID).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var feedback models.ChatFeedback
+	if err := doc.DataTo(&feedback); err != nil {
+		return nil, err
+	}
+	return &feedback, nil
+}
+
+func (r *chatFeedbackRepository) List(ctx context.Context, filter repository.ChatFeedbackFilter) ([]models.ChatFeedback, error) {
+	query := r.client.Collection("chat_feedback").Query
+	if filter.UserID != "" {
+		query = query.Where("userId", "==", filter.UserID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("createdAt", ">=", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("createdAt", "<", filter.To)
+	}
+
+	iter := query.OrderBy("createdAt", firestore.Asc).Documents(ctx)
+	defer iter.Stop()
+
+	var feedback []models.ChatFeedback
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var f models.ChatFeedback
+		if err := doc.DataTo(&f); err != nil {
+			return nil, err
+		}
+		feedback = append(feedback, f)
+	}
+	return feedback, nil
+}
+
+func (r *chatFeedbackRepository) DeleteBySession(ctx context.Context, userID, sessionID string) error {
+	iter := r.client.Collection("chat_feedback").
+		Where("userId", "==", userID).
+		Where("sessionId", "==", sessionID).
+		Documents(ctx)
+	defer iter.Stop()
+
+	// Firestore batches are capped at 500 writes
+	batch := r.client.Batch()
+	pending := 0
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		batch.Delete(doc.Ref)
+		pending++
+		if pending == 500 {
+			if _, err := batch.Commit(ctx); err != nil {
+				return err
+			}
+			batch = r.client.Batch()
+			pending = 0
+		}
+	}
+
+	if pending == 0 {
+		return nil
+	}
+	_, err := batch.Commit(ctx)
+	return err
+}
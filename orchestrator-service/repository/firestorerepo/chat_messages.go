@@ -0,0 +1,150 @@
+package firestorerepo
+
+import (
+	"context"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+	"orchestrator-service/search"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+type chatMessageRepository struct {
+	client *firestore.Client
+}
+
+// chatMessageDoc stores the message with its search terms, which Search
+// queries with array-contains-any.
+type chatMessageDoc struct {
+	models.ChatMessage
+	Terms []string `firestore:"terms"`
+}
+
+func (r *chatMessageRepository) Create(ctx context.Context, message *models.ChatMessage) error {
+	doc := chatMessageDoc{ChatMessage: *message, Terms: search.Terms(message.Text)}
+	_, err := r.client.Collection("chat_messages").Doc(message.ID).Set(ctx, doc)
+	return err
+}
+
+func (r *chatMessageRepository) Get(ctx context.Context, id string) (*models.ChatMessage, error) {
+	doc, err := r.client.Collection("chat_messages").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var message models.ChatMessage
+	if err := doc.DataTo(&message); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (r *chatMessageRepository) List(ctx context.Context, filter repository.ChatMessageFilter) ([]models.ChatMessage, error) {
+	query := r.client.Collection("chat_messages").Where("userId", "==", filter.UserID)
+	if filter.SessionID != "" {
+		query = query.Where("sessionId", "==", filter.SessionID)
+	}
+
+	iter := page(query, "timestamp", firestore.Desc, filter.After, filter.Limit).Documents(ctx)
+	defer iter.Stop()
+
+	var messages []models.ChatMessage
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var message models.ChatMessage
+		if err := doc.DataTo(&message); err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+func (r *chatMessageRepository) DeleteBySession(ctx context.Context, userID, sessionID string) error {
+	iter := r.client.Collection("chat_messages").
+		Where("userId", "==", userID).
+		Where("sessionId", "==", sessionID).
+		Documents(ctx)
+	defer iter.Stop()
+
+	// Firestore batches are capped at 500 writes
+	batch := r.client.Batch()
+	pending := 0
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		batch.Delete(doc.Ref)
+		pending++
+		if pending == 500 {
+			if _, err := batch.Commit(ctx); err != nil {
+				return err
+			}
+			batch = r.client.Batch()
+			pending = 0
+		}
+	}
+
+	if pending == 0 {
+		return nil
+	}
+	_, err := batch.Commit(ctx)
+	return err
+}
+
+// Search needs a composite index on userId, terms and timestamp. Messages
+// written before search existed have no terms and are not found.
+func (r *chatMessageRepository) Search(ctx context.Context, filter repository.ChatSearchFilter) ([]models.ChatMessage, error) {
+	if len(filter.Terms) == 0 {
+		return nil, nil
+	}
+
+	// array-contains-any takes at most 30 values
+	terms := filter.Terms
+	if len(terms) > 30 {
+		terms = terms[:30]
+	}
+
+	query := r.client.Collection("chat_messages").
+		Where("userId", "==", filter.UserID).
+		Where("terms", "array-contains-any", terms).
+		OrderBy("timestamp", firestore.Desc)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	var messages []models.ChatMessage
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var message models.ChatMessage
+		if err := doc.DataTo(&message); err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
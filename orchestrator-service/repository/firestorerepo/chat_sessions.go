@@ -0,0 +1,79 @@
+package firestorerepo
+
+import (
+	"context"
+	"time"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+type chatSessionRepository struct {
+	client *firestore.Client
+}
+
+func (r *chatSessionRepository) Create(ctx context.Context, session *models.ChatSession) error {
+	_, err := r.client.Collection("chat_sessions").Doc(session.ID).Create(ctx, session)
+	return err
+}
+
+func (r *chatSessionRepository) Get(ctx context.Context, id string) (*models.ChatSession, error) {
+	doc, err := r.client.Collection("chat_sessions").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var session models.ChatSession
+	if err := doc.DataTo(&session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *chatSessionRepository) UpdateTitle(ctx context.Context, id, title string) error {
+	_, err := r.client.Collection("chat_sessions").Doc(id).Update(ctx, []firestore.Update{
+		{Path: "title", Value: title},
+	})
+	return translateError(err)
+}
+
+func (r *chatSessionRepository) AddMessages(ctx context.Context, id string, n int, at time.Time) error {
+	_, err := r.client.Collection("chat_sessions").Doc(id).Update(ctx, []firestore.Update{
+		{Path: "messageCount", Value: firestore.Increment(n)},
+		{Path: "updatedAt", Value: at},
+	})
+	return translateError(err)
+}
+
+func (r *chatSessionRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.client.Collection("chat_sessions").Doc(id).Delete(ctx)
+	return err
+}
+
+func (r *chatSessionRepository) List(ctx context.Context, filter repository.ChatSessionFilter) ([]models.ChatSession, error) {
+	query := r.client.Collection("chat_sessions").Where("userId", "==", filter.UserID)
+
+	iter := page(query, "updatedAt", firestore.Desc, filter.After, filter.Limit).Documents(ctx)
+	defer iter.Stop()
+
+	var sessions []models.ChatSession
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var session models.ChatSession
+		if err := doc.DataTo(&session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
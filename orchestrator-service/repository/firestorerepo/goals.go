@@ -0,0 +1,107 @@
+package firestorerepo
+
+import (
+	"context"
+
+	"orchestrator-service/models"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+type goalRepository struct {
+	client *firestore.Client
+}
+
+func (r *goalRepository) Create(ctx context.Context, goal *models.Goal) error {
+	_, err := r.client.Collection("goals").Doc(goal.ID).Create(ctx, goal)
+	return err
+}
+
+func (r *goalRepository) Get(ctx context.Context, id string) (*models.Goal, error) {
+	doc, err := r.client.Collection("goals").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var goal models.Goal
+	if err := doc.DataTo(&goal); err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+func (r *goalRepository) Update(ctx context.Context, goal *models.Goal) error {
+	_, err := r.client.Collection("goals").Doc(goal.ID).Update(ctx, []firestore.Update{
+		{Path: "type", Value: goal.Type},
+		{Path: "period", Value: goal.Period},
+		{Path: "target", Value: goal.Target},
+		{Path: "unit", Value: goal.Unit},
+		{Path: "startDate", Value: goal.StartDate},
+		{Path: "endDate", Value: goal.EndDate},
+		{Path: "updatedAt", Value: goal.UpdatedAt},
+	})
+	return translateError(err)
+}
+
+func (r *goalRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.client.Collection("goals").Doc(id).Delete(ctx)
+	return err
+}
+
+func (r *goalRepository) List(ctx context.Context, userID string) ([]models.Goal, error) {
+	iter := r.client.Collection("goals").
+		Where("userId", "==", userID).
+		OrderBy("startDate", firestore.Asc).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var goals []models.Goal
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var goal models.Goal
+		if err := doc.DataTo(&goal); err != nil {
+			return nil, err
+		}
+		goals = append(goals, goal)
+	}
+	return goals, nil
+}
+
+func (r *goalRepository) AddChange(ctx context.Context, change *models.GoalChange) error {
+	_, err := r.client.Collection("goal_changes").Doc(change.ID).Set(ctx, change)
+	return err
+}
+
+func (r *goalRepository) Changes(ctx context.Context, goalID string) ([]models.GoalChange, error) {
+	iter := r.client.Collection("goal_changes").
+		Where("goalId", "==", goalID).
+		OrderBy("changedAt", firestore.Asc).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var changes []models.GoalChange
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var change models.GoalChange
+		if err := doc.DataTo(&change); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
@@ -0,0 +1,63 @@
+package firestorerepo
+
+import (
+	"context"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+type healthRecordRepository struct {
+	client *firestore.Client
+}
+
+func (r *healthRecordRepository) Create(ctx context.Context, record *models.HealthRecord) error {
+	_, err := r.client.Collection("health_records").Doc(record.ID).Set(ctx, record)
+	return err
+}
+
+func (r *healthRecordRepository) Get(ctx context.Context, id string) (*models.HealthRecord, error) {
+	doc, err := r.client.Collection("health_records").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var record models.HealthRecord
+	if err := doc.DataTo(&record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *healthRecordRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.client.Collection("health_records").Doc(id).Delete(ctx)
+	return err
+}
+
+func (r *healthRecordRepository) List(ctx context.Context, filter repository.HealthRecordFilter) ([]models.HealthRecord, error) {
+	query := r.client.Collection("health_records").Where("userId", "==", filter.UserID)
+
+	iter := page(query, "createdAt", firestore.Asc, filter.After, filter.Limit).Documents(ctx)
+	defer iter.Stop()
+
+	var records []models.HealthRecord
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var record models.HealthRecord
+		if err := doc.DataTo(&record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
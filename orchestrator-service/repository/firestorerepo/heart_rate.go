@@ -0,0 +1,80 @@
+package firestorerepo
+
+import (
+	"context"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+type heartRateAlertRepository struct {
+	client *firestore.Client
+}
+
+func (r *heartRateAlertRepository) Create(ctx context.Context, alert *models.HeartRateAlert) error {
+	_, err := r.client.Collection("heart_rate_alerts").Doc(alert.ID).Create(ctx, alert)
+	return err
+}
+
+func (r *heartRateAlertRepository) Get(ctx context.Context, id string) (*models.HeartRateAlert, error) {
+	doc, err := r.client.Collection("heart_rate_alerts").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var alert models.HeartRateAlert
+	if err := doc.DataTo(&alert); err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+func (r *heartRateAlertRepository) Update(ctx context.Context, alert *models.HeartRateAlert) error {
+	_, err := r.client.Collection("heart_rate_alerts").Doc(alert.ID).Update(ctx, []firestore.Update{
+		{Path: "threshold", Value: alert.Threshold},
+		{Path: "baseline", Value: alert.Baseline},
+		{Path: "peak", Value: alert.Peak},
+		{Path: "readings", Value: alert.Readings},
+		{Path: "start", Value: alert.Start},
+		{Path: "end", Value: alert.End},
+		{Path: "status", Value: alert.Status},
+		{Path: "updatedAt", Value: alert.UpdatedAt},
+		{Path: "acknowledgedAt", Value: alert.AcknowledgedAt},
+		{Path: "notifiedAt", Value: alert.NotifiedAt},
+	})
+	return translateError(err)
+}
+
+func (r *heartRateAlertRepository) List(ctx context.Context, filter repository.HeartRateAlertFilter) ([]models.HeartRateAlert, error) {
+	query := r.client.Collection("heart_rate_alerts").Where("userId", "==", filter.UserID)
+	if filter.Status != "" {
+		query = query.Where("status", "==", filter.Status)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("start", ">=", filter.From)
+	}
+
+	iter := page(query, "start", firestore.Desc, filter.After, filter.Limit).Documents(ctx)
+	defer iter.Stop()
+
+	var alerts []models.HeartRateAlert
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var alert models.HeartRateAlert
+		if err := doc.DataTo(&alert); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
@@ -0,0 +1,147 @@
+package firestorerepo
+
+import (
+	"context"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// activityRollupRepository keys documents by user, date, type and unit.
+// List needs a composite index on userId and date. The zone each user's
+// rollups were built in is kept apart, in one document per user.
+type activityRollupRepository struct {
+	client *firestore.Client
+}
+
+func (r *activityRollupRepository) ReplaceDay(ctx context.Context, userID, date string, rollups []models.ActivityRollup) error {
+	// A day holds a handful of rollups, well within one batch
+	batch := r.client.Batch()
+	pending := 0
+
+	kept := make(map[string]bool, len(rollups))
+	for i := range rollups {
+		rollup := &rollups[i]
+		id := userID + "_" + date + "_" + rollup.Type + "_" + rollup.Unit
+		batch.Set(r.client.Collection("activity_rollups").Doc(id), rollup)
+		kept[id] = true
+		pending++
+	}
+
+	iter := r.client.Collection("activity_rollups").
+		Where("userId", "==", userID).
+		Where("date", "==", date).
+		Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if !kept[doc.Ref.ID] {
+			batch.Delete(doc.Ref)
+			pending++
+		}
+	}
+
+	if pending == 0 {
+		return nil
+	}
+	_, err := batch.Commit(ctx)
+	return err
+}
+
+func (r *activityRollupRepository) List(ctx context.Context, filter repository.ActivityRollupFilter) ([]models.ActivityRollup, error) {
+	query := r.client.Collection("activity_rollups").Where("userId", "==", filter.UserID)
+	if filter.From != "" {
+		query = query.Where("date", ">=", filter.From)
+	}
+	if filter.To != "" {
+		query = query.Where("date", "<=", filter.To)
+	}
+	if filter.Type != "" {
+		query = query.Where("type", "==", filter.Type)
+	}
+
+	iter := query.OrderBy("date", firestore.Asc).Documents(ctx)
+	defer iter.Stop()
+
+	var rollups []models.ActivityRollup
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var rollup models.ActivityRollup
+		if err := doc.DataTo(&rollup); err != nil {
+			return nil, err
+		}
+		rollups = append(rollups, rollup)
+	}
+	return rollups, nil
+}
+
+func (r *activityRollupRepository) DeleteByUser(ctx context.Context, userID string) error {
+	iter := r.client.Collection("activity_rollups").
+		Where("userId", "==", userID).
+		Documents(ctx)
+	defer iter.Stop()
+
+	// Firestore batches are capped at 500 writes
+	batch := r.client.Batch()
+	pending := 0
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		batch.Delete(doc.Ref)
+		pending++
+		if pending == 500 {
+			if _, err := batch.Commit(ctx); err != nil {
+				return err
+			}
+			batch = r.client.Batch()
+			pending = 0
+		}
+	}
+
+	if pending == 0 {
+		return nil
+	}
+	_, err := batch.Commit(ctx)
+	return err
+}
+
+func (r *activityRollupRepository) Zone(ctx context.Context, userID string) (string, error) {
+	doc, err := r.client.Collection("activity_rollup_zones").Doc(userID).Get(ctx)
+	if err != nil {
+		if err = translateError(err); err == repository.ErrNotFound {
+			return "", nil
+		}
+		return "", err
+	}
+
+	zone, _ := doc.Data()["zone"].(string)
+	return zone, nil
+}
+
+func (r *activityRollupRepository) SetZone(ctx context.Context, userID, zone string) error {
+	_, err := r.client.Collection("activity_rollup_zones").Doc(userID).Set(ctx, map[string]any{"zone": zone})
+	return err
+}
@@ -0,0 +1,50 @@
+// Package firestorerepo implements the repository interfaces on top of
+// Cloud Firestore.
+package firestorerepo
+
+import (
+	"orchestrator-service/repository"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func NewStore(client *firestore.Client) *repository.Store {
+	return &repository.Store{
+		Users:           &userRepository{client: client},
+		Activities:      &activityRepository{client: client},
+		Rollups:         &activityRollupRepository{client: client},
+		HealthRecords:   &healthRecordRepository{client: client},
+		ChatMessages:    &chatMessageRepository{client: client},
+		ChatSessions:    &chatSessionRepository{client: client},
+		ChatFeedback:    &chatFeedbackRepository{client: client},
+		Goals:           &goalRepository{client: client},
+		Achievements:    &achievementRepository{client: client},
+		HeartRateAlerts: &heartRateAlertRepository{client: client},
+		RefreshTokens:   &refreshTokenRepository{client: client},
+		RevokedTokens:   &revokedTokenRepository{client: client},
+		ActionTokens:    &actionTokenRepository{client: client},
+	}
+}
+
+// page orders a query by field and then document ID, continues after the
+// cursor if there is one and applies the limit.
+func page(query firestore.Query, field string, dir firestore.Direction, after *repository.Cursor, limit int) firestore.Query {
+	query = query.OrderBy(field, dir).OrderBy(firestore.DocumentID, dir)
+	if after != nil {
+		query = query.StartAfter(after.Time, after.ID)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	return query
+}
+
+// translateError maps Firestore's NotFound status to repository.ErrNotFound.
+func translateError(err error) error {
+	if status.Code(err) == codes.NotFound {
+		return repository.ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,156 @@
+package firestorerepo
+
+import (
+	"context"
+	"time"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+type refreshTokenRepository struct {
+	client *firestore.Client
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	_, err := r.client.Collection("refresh_tokens").Doc(token.ID).Set(ctx, token)
+	return err
+}
+
+func (r *refreshTokenRepository) Get(ctx context.Context, id string) (*models.RefreshToken, error) {
+	doc, err := r.client.Collection("refresh_tokens").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var token models.RefreshToken
+	if err := doc.DataTo(&token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *refreshTokenRepository) Revoke(ctx context.Context, id string, at time.Time, replacedBy string) error {
+	_, err := r.client.Collection("refresh_tokens").Doc(id).Update(ctx, []firestore.Update{
+		{Path: "revokedAt", Value: at},
+		{Path: "replacedBy", Value: replacedBy},
+	})
+	return translateError(err)
+}
+
+func (r *refreshTokenRepository) Rotate(ctx context.Context, id string, at time.Time, replacedBy string) error {
+	ref := r.client.Collection("refresh_tokens").Doc(id)
+
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return translateError(err)
+		}
+		var token models.RefreshToken
+		if err := doc.DataTo(&token); err != nil {
+			return err
+		}
+		if token.Revoked() {
+			return repository.ErrNotFound
+		}
+
+		return tx.Update(ref, []firestore.Update{
+			{Path: "revokedAt", Value: at},
+			{Path: "replacedBy", Value: replacedBy},
+		})
+	})
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	return r.revokeWhere(ctx, "familyId", familyID, at)
+}
+
+func (r *refreshTokenRepository) RevokeAllForUser(ctx context.Context, userID string, at time.Time) error {
+	return r.revokeWhere(ctx, "userId", userID, at)
+}
+
+func (r *refreshTokenRepository) revokeWhere(ctx context.Context, field, value string, at time.Time) error {
+	iter := r.client.Collection("refresh_tokens").Where(field, "==", value).Documents(ctx)
+	defer iter.Stop()
+
+	batch := r.client.Batch()
+	pending := 0
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		batch.Update(doc.Ref, []firestore.Update{{Path: "revokedAt", Value: at}})
+		pending++
+	}
+
+	if pending == 0 {
+		return nil
+	}
+	_, err := batch.Commit(ctx)
+	return err
+}
+
+type revokedTokenRepository struct {
+	client *firestore.Client
+}
+
+func (r *revokedTokenRepository) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	_, err := r.client.Collection("revoked_tokens").Doc(jti).Set(ctx, map[string]interface{}{
+		"jti":       jti,
+		"expiresAt": expiresAt,
+	})
+	return err
+}
+
+func (r *revokedTokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	_, err := r.client.Collection("revoked_tokens").Doc(jti).Get(ctx)
+	if err == nil {
+		return true, nil
+	}
+	if err := translateError(err); err != repository.ErrNotFound {
+		return false, err
+	}
+	return false, nil
+}
+
+type actionTokenRepository struct {
+	client *firestore.Client
+}
+
+func (r *actionTokenRepository) Create(ctx context.Context, token *models.ActionToken) error {
+	_, err := r.client.Collection("action_tokens").Doc(token.ID).Set(ctx, token)
+	return err
+}
+
+func (r *actionTokenRepository) Consume(ctx context.Context, id string, at time.Time) (*models.ActionToken, error) {
+	ref := r.client.Collection("action_tokens").Doc(id)
+
+	var token models.ActionToken
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return translateError(err)
+		}
+		if err := doc.DataTo(&token); err != nil {
+			return err
+		}
+		if !token.UsedAt.IsZero() {
+			return repository.ErrNotFound
+		}
+
+		token.UsedAt = at
+		return tx.Update(ref, []firestore.Update{{Path: "usedAt", Value: at}})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
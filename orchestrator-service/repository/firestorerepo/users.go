@@ -0,0 +1,75 @@
+package firestorerepo
+
+import (
+	"context"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+type userRepository struct {
+	client *firestore.Client
+}
+
+func (r *userRepository) Get(ctx context.Context, id string) (*models.User, error) {
+	doc, err := r.client.Collection("users").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var user models.User
+	if err := doc.DataTo(&user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	iter := r.client.Collection("users").Where("email", "==", email).Limit(1).Documents(ctx)
+	defer iter.Stop()
+
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := doc.DataTo(&user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) Save(ctx context.Context, user *models.User) error {
+	_, err := r.client.Collection("users").Doc(user.ID).Set(ctx, user)
+	return err
+}
+
+func (r *userRepository) List(ctx context.Context) ([]models.User, error) {
+	iter := r.client.Collection("users").Documents(ctx)
+	defer iter.Stop()
+
+	var users []models.User
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var user models.User
+		if err := doc.DataTo(&user); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
@@ -0,0 +1,48 @@
+package memrepo
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"orchestrator-service/models"
+)
+
+type achievementRepository struct {
+	mu           sync.RWMutex
+	achievements map[string]map[string]models.Achievement // User ID -> badge ID
+}
+
+func newAchievementRepository() *achievementRepository {
+	return &achievementRepository{achievements: make(map[string]map[string]models.Achievement)}
+}
+
+func (r *achievementRepository) Award(ctx context.Context, achievement *models.Achievement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	earned := r.achievements[achievement.UserID]
+	if earned == nil {
+		earned = make(map[string]models.Achievement)
+		r.achievements[achievement.UserID] = earned
+	}
+	if _, ok := earned[achievement.BadgeID]; !ok {
+		earned[achievement.BadgeID] = *achievement
+	}
+	return nil
+}
+
+func (r *achievementRepository) List(ctx context.Context, userID string) ([]models.Achievement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var achievements []models.Achievement
+	for _, achievement := range r.achievements[userID] {
+		achievements = append(achievements, achievement)
+	}
+
+	sort.Slice(achievements, func(i, j int) bool {
+		return achievements[i].EarnedAt.Before(achievements[j].EarnedAt)
+	})
+	return achievements, nil
+}
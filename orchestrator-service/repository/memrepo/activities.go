@@ -0,0 +1,84 @@
+package memrepo
+
+import (
+	"context"
+	"sync"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+)
+
+type activityRepository struct {
+	mu         sync.RWMutex
+	activities map[string]models.Activity
+}
+
+func newActivityRepository() *activityRepository {
+	return &activityRepository{activities: make(map[string]models.Activity)}
+}
+
+func (r *activityRepository) Create(ctx context.Context, activity *models.Activity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.activities[activity.ID] = *activity
+	return nil
+}
+
+func (r *activityRepository) Get(ctx context.Context, id string) (*models.Activity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	activity, ok := r.activities[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &activity, nil
+}
+
+func (r *activityRepository) Update(ctx context.Context, activity *models.Activity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.activities[activity.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	r.activities[activity.ID] = *activity
+	return nil
+}
+
+func (r *activityRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.activities, id)
+	return nil
+}
+
+func (r *activityRepository) List(ctx context.Context, filter repository.ActivityFilter) ([]models.Activity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var activities []models.Activity
+	for _, activity := range r.activities {
+		if activity.UserID != filter.UserID {
+			continue
+		}
+		if filter.Type != "" && activity.Type != filter.Type {
+			continue
+		}
+		if !filter.From.IsZero() && activity.Date.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !activity.Date.Before(filter.To) {
+			continue
+		}
+		activities = append(activities, activity)
+	}
+
+	return page(activities, activityCursor, false, filter.After, filter.Limit), nil
+}
+
+func activityCursor(activity models.Activity) repository.Cursor {
+	return repository.Cursor{Time: activity.Date, ID: activity.ID}
+}
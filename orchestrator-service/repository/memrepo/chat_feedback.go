@@ -0,0 +1,74 @@
+package memrepo
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+)
+
+type chatFeedbackRepository struct {
+	mu       sync.RWMutex
+	feedback map[string]models.ChatFeedback
+}
+
+func newChatFeedbackRepository() *chatFeedbackRepository {
+	return &chatFeedbackRepository{feedback: make(map[string]models.ChatFeedback)}
+}
+
+func (r *chatFeedbackRepository) Save(ctx context.Context, feedback *models.ChatFeedback) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.feedback[feedback.MessageID] = *feedback
+	return nil
+}
+
+func (r *chatFeedbackRepository) Get(ctx context.Context, messageID string) (*models.ChatFeedback, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	feedback, ok := r.feedback[messageID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &feedback, nil
+}
+
+func (r *chatFeedbackRepository) List(ctx context.Context, filter repository.ChatFeedbackFilter) ([]models.ChatFeedback, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var feedback []models.ChatFeedback
+	for _, f := range r.feedback {
+		if filter.UserID != "" && f.UserID != filter.UserID {
+			continue
+		}
+		if !filter.From.IsZero() && f.CreatedAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !f.CreatedAt.Before(filter.To) {
+			continue
+		}
+		feedback = append(feedback, f)
+	}
+
+	sort.Slice(feedback, func(i, j int) bool {
+		return feedback[i].CreatedAt.Before(feedback[j].CreatedAt)
+	})
+	return feedback, nil
+}
+
+func (r *chatFeedbackRepository) DeleteBySession(ctx context.Context, userID, sessionID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, f := range r.feedback {
+		if f.UserID == userID && f.SessionID == sessionID {
+			delete(r.feedback, id)
+		}
+	}
+	return nil
+}
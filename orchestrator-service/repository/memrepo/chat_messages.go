@@ -0,0 +1,103 @@
+package memrepo
+
+import (
+	"context"
+	"sync"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+	"orchestrator-service/search"
+)
+
+type chatMessageRepository struct {
+	mu       sync.RWMutex
+	messages map[string]models.ChatMessage
+	terms    map[string]map[string]bool // message ID -> search terms
+}
+
+func newChatMessageRepository() *chatMessageRepository {
+	return &chatMessageRepository{
+		messages: make(map[string]models.ChatMessage),
+		terms:    make(map[string]map[string]bool),
+	}
+}
+
+func (r *chatMessageRepository) Create(ctx context.Context, message *models.ChatMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	terms := make(map[string]bool)
+	for _, term := range search.Terms(message.Text) {
+		terms[term] = true
+	}
+
+	r.messages[message.ID] = *message
+	r.terms[message.ID] = terms
+	return nil
+}
+
+func (r *chatMessageRepository) Get(ctx context.Context, id string) (*models.ChatMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	message, ok := r.messages[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &message, nil
+}
+
+func (r *chatMessageRepository) List(ctx context.Context, filter repository.ChatMessageFilter) ([]models.ChatMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var messages []models.ChatMessage
+	for _, message := range r.messages {
+		if message.UserID != filter.UserID {
+			continue
+		}
+		if filter.SessionID != "" && message.SessionID != filter.SessionID {
+			continue
+		}
+		messages = append(messages, message)
+	}
+
+	return page(messages, chatMessageCursor, true, filter.After, filter.Limit), nil
+}
+
+func chatMessageCursor(message models.ChatMessage) repository.Cursor {
+	return repository.Cursor{Time: message.Timestamp, ID: message.ID}
+}
+
+func (r *chatMessageRepository) DeleteBySession(ctx context.Context, userID, sessionID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, message := range r.messages {
+		if message.UserID == userID && message.SessionID == sessionID {
+			delete(r.messages, id)
+			delete(r.terms, id)
+		}
+	}
+	return nil
+}
+
+func (r *chatMessageRepository) Search(ctx context.Context, filter repository.ChatSearchFilter) ([]models.ChatMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var messages []models.ChatMessage
+	for id, message := range r.messages {
+		if message.UserID != filter.UserID {
+			continue
+		}
+		for _, term := range filter.Terms {
+			if r.terms[id][term] {
+				messages = append(messages, message)
+				break
+			}
+		}
+	}
+
+	return page(messages, chatMessageCursor, true, nil, filter.Limit), nil
+}
@@ -0,0 +1,93 @@
+package memrepo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+)
+
+type chatSessionRepository struct {
+	mu       sync.RWMutex
+	sessions map[string]models.ChatSession
+}
+
+func newChatSessionRepository() *chatSessionRepository {
+	return &chatSessionRepository{sessions: make(map[string]models.ChatSession)}
+}
+
+func (r *chatSessionRepository) Create(ctx context.Context, session *models.ChatSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.sessions[session.ID]; exists {
+		return errors.New("chat session already exists")
+	}
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *chatSessionRepository) Get(ctx context.Context, id string) (*models.ChatSession, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &session, nil
+}
+
+func (r *chatSessionRepository) UpdateTitle(ctx context.Context, id, title string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	session.Title = title
+	r.sessions[id] = session
+	return nil
+}
+
+func (r *chatSessionRepository) AddMessages(ctx context.Context, id string, n int, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	session.MessageCount += n
+	session.UpdatedAt = at
+	r.sessions[id] = session
+	return nil
+}
+
+func (r *chatSessionRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.sessions, id)
+	return nil
+}
+
+func (r *chatSessionRepository) List(ctx context.Context, filter repository.ChatSessionFilter) ([]models.ChatSession, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var sessions []models.ChatSession
+	for _, session := range r.sessions {
+		if session.UserID == filter.UserID {
+			sessions = append(sessions, session)
+		}
+	}
+
+	return page(sessions, func(session models.ChatSession) repository.Cursor {
+		return repository.Cursor{Time: session.UpdatedAt, ID: session.ID}
+	}, true, filter.After, filter.Limit), nil
+}
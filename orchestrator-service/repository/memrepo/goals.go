@@ -0,0 +1,100 @@
+package memrepo
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+)
+
+type goalRepository struct {
+	mu      sync.RWMutex
+	goals   map[string]models.Goal
+	changes []models.GoalChange
+}
+
+func newGoalRepository() *goalRepository {
+	return &goalRepository{goals: make(map[string]models.Goal)}
+}
+
+func (r *goalRepository) Create(ctx context.Context, goal *models.Goal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.goals[goal.ID] = *goal
+	return nil
+}
+
+func (r *goalRepository) Get(ctx context.Context, id string) (*models.Goal, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	goal, ok := r.goals[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &goal, nil
+}
+
+func (r *goalRepository) Update(ctx context.Context, goal *models.Goal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.goals[goal.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	r.goals[goal.ID] = *goal
+	return nil
+}
+
+func (r *goalRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.goals, id)
+	return nil
+}
+
+func (r *goalRepository) List(ctx context.Context, userID string) ([]models.Goal, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var goals []models.Goal
+	for _, goal := range r.goals {
+		if goal.UserID == userID {
+			goals = append(goals, goal)
+		}
+	}
+
+	sort.Slice(goals, func(i, j int) bool {
+		if goals[i].StartDate != goals[j].StartDate {
+			return goals[i].StartDate < goals[j].StartDate
+		}
+		return goals[i].ID < goals[j].ID
+	})
+	return goals, nil
+}
+
+func (r *goalRepository) AddChange(ctx context.Context, change *models.GoalChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.changes = append(r.changes, *change)
+	return nil
+}
+
+func (r *goalRepository) Changes(ctx context.Context, goalID string) ([]models.GoalChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Changes are appended in order
+	var changes []models.GoalChange
+	for _, change := range r.changes {
+		if change.GoalID == goalID {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,61 @@
+package memrepo
+
+import (
+	"context"
+	"sync"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+)
+
+type healthRecordRepository struct {
+	mu      sync.RWMutex
+	records map[string]models.HealthRecord
+}
+
+func newHealthRecordRepository() *healthRecordRepository {
+	return &healthRecordRepository{records: make(map[string]models.HealthRecord)}
+}
+
+func (r *healthRecordRepository) Create(ctx context.Context, record *models.HealthRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records[record.ID] = *record
+	return nil
+}
+
+func (r *healthRecordRepository) Get(ctx context.Context, id string) (*models.HealthRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	record, ok := r.records[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &record, nil
+}
+
+func (r *healthRecordRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.records, id)
+	return nil
+}
+
+func (r *healthRecordRepository) List(ctx context.Context, filter repository.HealthRecordFilter) ([]models.HealthRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var records []models.HealthRecord
+	for _, record := range r.records {
+		if record.UserID == filter.UserID {
+			records = append(records, record)
+		}
+	}
+
+	return page(records, func(record models.HealthRecord) repository.Cursor {
+		return repository.Cursor{Time: record.CreatedAt, ID: record.ID}
+	}, false, filter.After, filter.Limit), nil
+}
@@ -0,0 +1,75 @@
+package memrepo
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+)
+
+type heartRateAlertRepository struct {
+	mu     sync.RWMutex
+	alerts map[string]models.HeartRateAlert
+}
+
+func newHeartRateAlertRepository() *heartRateAlertRepository {
+	return &heartRateAlertRepository{alerts: make(map[string]models.HeartRateAlert)}
+}
+
+func (r *heartRateAlertRepository) Create(ctx context.Context, alert *models.HeartRateAlert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.alerts[alert.ID]; exists {
+		return errors.New("heart rate alert already exists")
+	}
+	r.alerts[alert.ID] = *alert
+	return nil
+}
+
+func (r *heartRateAlertRepository) Get(ctx context.Context, id string) (*models.HeartRateAlert, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	alert, ok := r.alerts[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &alert, nil
+}
+
+func (r *heartRateAlertRepository) Update(ctx context.Context, alert *models.HeartRateAlert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.alerts[alert.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	r.alerts[alert.ID] = *alert
+	return nil
+}
+
+func (r *heartRateAlertRepository) List(ctx context.Context, filter repository.HeartRateAlertFilter) ([]models.HeartRateAlert, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var alerts []models.HeartRateAlert
+	for _, alert := range r.alerts {
+		if alert.UserID != filter.UserID {
+			continue
+		}
+		if filter.Status != "" && alert.Status != filter.Status {
+			continue
+		}
+		if !filter.From.IsZero() && alert.Start.Before(filter.From) {
+			continue
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return page(alerts, func(alert models.HeartRateAlert) repository.Cursor {
+		return repository.Cursor{Time: alert.Start, ID: alert.ID}
+	}, true, filter.After, filter.Limit), nil
+}
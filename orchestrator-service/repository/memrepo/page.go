@@ -0,0 +1,47 @@
+package memrepo
+
+import (
+	"sort"
+	"strings"
+
+	"orchestrator-service/repository"
+)
+
+// page sorts items by their (time, ID) key, ascending or descending, skips
+// everything up to and including the after cursor, and applies the limit.
+// A zero limit returns the rest.
+func page[T any](items []T, key func(T) repository.Cursor, desc bool, after *repository.Cursor, limit int) []T {
+	// precedes reports whether a comes before b in listing order
+	precedes := func(a, b repository.Cursor) bool {
+		c := compareCursors(a, b)
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return precedes(key(items[i]), key(items[j]))
+	})
+
+	if after != nil {
+		start := sort.Search(len(items), func(i int) bool {
+			return precedes(*after, key(items[i]))
+		})
+		items = items[start:]
+	}
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+func compareCursors(a, b repository.Cursor) int {
+	switch {
+	case a.Time.Before(b.Time):
+		return -1
+	case a.Time.After(b.Time):
+		return 1
+	}
+	return strings.Compare(a.ID, b.ID)
+}
@@ -0,0 +1,85 @@
+package memrepo
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+)
+
+type activityRollupRepository struct {
+	mu      sync.RWMutex
+	rollups map[string]map[string][]models.ActivityRollup // User ID -> date
+	zones   map[string]string                             // User ID -> zone built in
+}
+
+func newActivityRollupRepository() *activityRollupRepository {
+	return &activityRollupRepository{
+		rollups: make(map[string]map[string][]models.ActivityRollup),
+		zones:   make(map[string]string),
+	}
+}
+
+func (r *activityRollupRepository) ReplaceDay(ctx context.Context, userID, date string, rollups []models.ActivityRollup) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	days := r.rollups[userID]
+	if days == nil {
+		days = make(map[string][]models.ActivityRollup)
+		r.rollups[userID] = days
+	}
+	if len(rollups) == 0 {
+		delete(days, date)
+		return nil
+	}
+	days[date] = append([]models.ActivityRollup(nil), rollups...)
+	return nil
+}
+
+func (r *activityRollupRepository) List(ctx context.Context, filter repository.ActivityRollupFilter) ([]models.ActivityRollup, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var rollups []models.ActivityRollup
+	for date, day := range r.rollups[filter.UserID] {
+		if date < filter.From || (filter.To != "" && date > filter.To) {
+			continue
+		}
+		for _, rollup := range day {
+			if filter.Type == "" || rollup.Type == filter.Type {
+				rollups = append(rollups, rollup)
+			}
+		}
+	}
+
+	sort.Slice(rollups, func(i, j int) bool {
+		return rollups[i].Date < rollups[j].Date
+	})
+	return rollups, nil
+}
+
+func (r *activityRollupRepository) DeleteByUser(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.rollups, userID)
+	return nil
+}
+
+func (r *activityRollupRepository) Zone(ctx context.Context, userID string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.zones[userID], nil
+}
+
+func (r *activityRollupRepository) SetZone(ctx context.Context, userID, zone string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.zones[userID] = zone
+	return nil
+}
@@ -0,0 +1,23 @@
+// Package memrepo implements the repository interfaces in process memory.
+// Data is lost on restart; it is meant for tests and local development.
+package memrepo
+
+import "orchestrator-service/repository"
+
+func NewStore() *repository.Store {
+	return &repository.Store{
+		Users:           newUserRepository(),
+		Activities:      newActivityRepository(),
+		Rollups:         newActivityRollupRepository(),
+		HealthRecords:   newHealthRecordRepository(),
+		ChatMessages:    newChatMessageRepository(),
+		ChatSessions:    newChatSessionRepository(),
+		ChatFeedback:    newChatFeedbackRepository(),
+		Goals:           newGoalRepository(),
+		Achievements:    newAchievementRepository(),
+		HeartRateAlerts: newHeartRateAlertRepository(),
+		RefreshTokens:   newRefreshTokenRepository(),
+		RevokedTokens:   newRevokedTokenRepository(),
+		ActionTokens:    newActionTokenRepository(),
+	}
+}
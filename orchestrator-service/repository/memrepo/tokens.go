@@ -0,0 +1,156 @@
+package memrepo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+)
+
+type refreshTokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[string]models.RefreshToken
+}
+
+func newRefreshTokenRepository() *refreshTokenRepository {
+	return &refreshTokenRepository{tokens: make(map[string]models.RefreshToken)}
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens[token.ID] = *token
+	return nil
+}
+
+func (r *refreshTokenRepository) Get(ctx context.Context, id string) (*models.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	token, ok := r.tokens[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &token, nil
+}
+
+func (r *refreshTokenRepository) Revoke(ctx context.Context, id string, at time.Time, replacedBy string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	token.RevokedAt = at
+	token.ReplacedBy = replacedBy
+	r.tokens[id] = token
+	return nil
+}
+
+func (r *refreshTokenRepository) Rotate(ctx context.Context, id string, at time.Time, replacedBy string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok || token.Revoked() {
+		return repository.ErrNotFound
+	}
+	token.RevokedAt = at
+	token.ReplacedBy = replacedBy
+	r.tokens[id] = token
+	return nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if token.FamilyID == familyID {
+			token.RevokedAt = at
+			r.tokens[id] = token
+		}
+	}
+	return nil
+}
+
+func (r *refreshTokenRepository) RevokeAllForUser(ctx context.Context, userID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if token.UserID == userID && !token.Revoked() {
+			token.RevokedAt = at
+			r.tokens[id] = token
+		}
+	}
+	return nil
+}
+
+type revokedTokenRepository struct {
+	mu      sync.RWMutex
+	revoked map[string]time.Time
+}
+
+func newRevokedTokenRepository() *revokedTokenRepository {
+	return &revokedTokenRepository{revoked: make(map[string]time.Time)}
+}
+
+func (r *revokedTokenRepository) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Drop entries whose tokens have expired on their own
+	now := time.Now()
+	for id, exp := range r.revoked {
+		if exp.Before(now) {
+			delete(r.revoked, id)
+		}
+	}
+
+	r.revoked[jti] = expiresAt
+	return nil
+}
+
+func (r *revokedTokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.revoked[jti]
+	return ok, nil
+}
+
+type actionTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]models.ActionToken
+}
+
+func newActionTokenRepository() *actionTokenRepository {
+	return &actionTokenRepository{tokens: make(map[string]models.ActionToken)}
+}
+
+func (r *actionTokenRepository) Create(ctx context.Context, token *models.ActionToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens[token.ID] = *token
+	return nil
+}
+
+func (r *actionTokenRepository) Consume(ctx context.Context, id string, at time.Time) (*models.ActionToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok || !token.UsedAt.IsZero() {
+		return nil, repository.ErrNotFound
+	}
+
+	token.UsedAt = at
+	r.tokens[id] = token
+	return &token, nil
+}
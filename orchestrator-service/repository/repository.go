@@ -0,0 +1,252 @@
+// Package repository defines the storage interfaces used by the handlers.
+// Backends live in the sub-packages (firestorerepo, sqlrepo, memrepo); the
+// STORAGE_BACKEND environment variable picks one at startup, and the SQL
+// backends take their connection string from DATABASE_URL.
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"orchestrator-service/models"
+)
+
+// ErrNotFound is returned when a requested document does not exist.
+var ErrNotFound = errors.New("not found")
+
+// Cursor is a position in a paginated listing: the sort key and ID of the
+// last item already returned. Listings order by key and then ID, so the
+// position is exact even when keys tie.
+type Cursor struct {
+	Time time.Time
+	ID   string
+}
+
+type UserRepository interface {
+	Get(ctx context.Context, id string) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	// Save creates the user or overwrites an existing one with the same ID.
+	Save(ctx context.Context, user *models.User) error
+	// List returns every user, for work done across all of them at startup.
+	List(ctx context.Context) ([]models.User, error)
+}
+
+// ActivityFilter selects a user's activities. Zero From/To leave that end of
+// the date range open; To is exclusive. An empty Type matches every type.
+// After continues a listing from a cursor on (date, ID), and a zero Limit
+// returns every match.
+type ActivityFilter struct {
+	UserID string
+	Type   string
+	From   time.Time
+	To     time.Time
+	Limit  int
+	After  *Cursor
+}
+
+type ActivityRepository interface {
+	Create(ctx context.Context, activity *models.Activity) error
+	Get(ctx context.Context, id string) (*models.Activity, error)
+	// Update overwrites an existing activity.
+	Update(ctx context.Context, activity *models.Activity) error
+	Delete(ctx context.Context, id string) error
+	// List returns matching activities ordered by date, oldest first.
+	List(ctx context.Context, filter ActivityFilter) ([]models.Activity, error)
+}
+
+// ActivityRollupFilter selects a user's rollups between two dates
+// (YYYY-MM-DD, both inclusive). An empty bound leaves that end open and an
+// empty Type matches every type.
+type ActivityRollupFilter struct {
+	UserID string
+	Type   string
+	From   string
+	To     string
+}
+
+type ActivityRollupRepository interface {
+	// ReplaceDay sets the rollups of one of the user's days, dropping any
+	// for types no longer logged that day.
+	ReplaceDay(ctx context.Context, userID, date string, rollups []models.ActivityRollup) error
+	// List returns matching rollups ordered by date.
+	List(ctx context.Context, filter ActivityRollupFilter) ([]models.ActivityRollup, error)
+	DeleteByUser(ctx context.Context, userID string) error
+	// Zone is the time zone the user's rollups were built in, or empty
+	// while they are not built or out of date.
+	Zone(ctx context.Context, userID string) (string, error)
+	// SetZone records the time zone the user's rollups were built in; an
+	// empty zone marks them out of date.
+	SetZone(ctx context.Context, userID, zone string) error
+}
+
+// HealthRecordFilter pages through a user's records. After is a cursor on
+// (createdAt, ID); a zero Limit returns every record.
+type HealthRecordFilter struct {
+	UserID string
+	Limit  int
+	After  *Cursor
+}
+
+type HealthRecordRepository interface {
+	Create(ctx context.Context, record *models.HealthRecord) error
+	Get(ctx context.Context, id string) (*models.HealthRecord, error)
+	Delete(ctx context.Context, id string) error
+	// List returns the user's records in the order they were created.
+	List(ctx context.Context, filter HealthRecordFilter) ([]models.HealthRecord, error)
+}
+
+// ChatMessageFilter selects a user's messages, optionally within one
+// session. After is a cursor on (timestamp, ID) and, as the listing is
+// newest first, continues with older messages. A zero Limit returns every
+// match.
+type ChatMessageFilter struct {
+	UserID    string
+	SessionID string
+	Limit     int
+	After     *Cursor
+}
+
+// ChatSearchFilter selects a user's messages containing any of the given
+// search terms (as produced by search.Terms), up to Limit of them.
+type ChatSearchFilter struct {
+	UserID string
+	Terms  []string
+	Limit  int
+}
+
+// ChatMessageRepository also keeps the search index: Create indexes the
+// message's terms and DeleteBySession drops them again.
+type ChatMessageRepository interface {
+	Create(ctx context.Context, message *models.ChatMessage) error
+	Get(ctx context.Context, id string) (*models.ChatMessage, error)
+	// List returns matching messages ordered by timestamp, newest first.
+	List(ctx context.Context, filter ChatMessageFilter) ([]models.ChatMessage, error)
+	DeleteBySession(ctx context.Context, userID, sessionID string) error
+	// Search returns messages matching the filter, newest first, unranked.
+	Search(ctx context.Context, filter ChatSearchFilter) ([]models.ChatMessage, error)
+}
+
+// ChatSessionFilter pages through a user's sessions, most recently active
+// first. After is a cursor on (updatedAt, ID).
+type ChatSessionFilter struct {
+	UserID string
+	Limit  int
+	After  *Cursor
+}
+
+type ChatSessionRepository interface {
+	Create(ctx context.Context, session *models.ChatSession) error
+	Get(ctx context.Context, id string) (*models.ChatSession, error)
+	UpdateTitle(ctx context.Context, id, title string) error
+	// AddMessages bumps the message count by n and sets UpdatedAt.
+	AddMessages(ctx context.Context, id string, n int, at time.Time) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, filter ChatSessionFilter) ([]models.ChatSession, error)
+}
+
+// ChatFeedbackFilter selects feedback created in [From, To), of one user or,
+// with an empty UserID, of everyone. Zero bounds leave that end open.
+type ChatFeedbackFilter struct {
+	UserID string
+	From   time.Time
+	To     time.Time
+}
+
+type ChatFeedbackRepository interface {
+	// Save creates or replaces the feedback for feedback.MessageID.
+	Save(ctx context.Context, feedback *models.ChatFeedback) error
+	Get(ctx context.Context, messageID string) (*models.ChatFeedback, error)
+	// List returns matching feedback ordered by creation time, oldest first.
+	List(ctx context.Context, filter ChatFeedbackFilter) ([]models.ChatFeedback, error)
+	DeleteBySession(ctx context.Context, userID, sessionID string) error
+}
+
+// GoalRepository stores goals and, separately, the history of changes to
+// them, which outlives deleted goals.
+type GoalRepository interface {
+	Create(ctx context.Context, goal *models.Goal) error
+	Get(ctx context.Context, id string) (*models.Goal, error)
+	// Update overwrites an existing goal.
+	Update(ctx context.Context, goal *models.Goal) error
+	Delete(ctx context.Context, id string) error
+	// List returns the user's goals ordered by start date.
+	List(ctx context.Context, userID string) ([]models.Goal, error)
+	AddChange(ctx context.Context, change *models.GoalChange) error
+	// Changes returns the changes to a goal, oldest first.
+	Changes(ctx context.Context, goalID string) ([]models.GoalChange, error)
+}
+
+type AchievementRepository interface {
+	// Award records a badge as earned. Awarding a badge the user already
+	// has keeps the original date.
+	Award(ctx context.Context, achievement *models.Achievement) error
+	List(ctx context.Context, userID string) ([]models.Achievement, error)
+}
+
+// HeartRateAlertFilter pages through a user's alerts, latest first. An
+// empty Status matches every alert, and a zero From leaves the start of
+// the range open. After is a cursor on (start, ID).
+type HeartRateAlertFilter struct {
+	UserID string
+	Status string
+	From   time.Time
+	Limit  int
+	After  *Cursor
+}
+
+type HeartRateAlertRepository interface {
+	Create(ctx context.Context, alert *models.HeartRateAlert) error
+	Get(ctx context.Context, id string) (*models.HeartRateAlert, error)
+	// Update overwrites an existing alert.
+	Update(ctx context.Context, alert *models.HeartRateAlert) error
+	List(ctx context.Context, filter HeartRateAlertFilter) ([]models.HeartRateAlert, error)
+}
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	// Get looks a token up by its hash.
+	Get(ctx context.Context, id string) (*models.RefreshToken, error)
+	// Revoke marks a single token revoked, recording its successor if the
+	// token was rotated.
+	Revoke(ctx context.Context, id string, at time.Time, replacedBy string) error
+	// Rotate revokes a token in favour of its successor only while it is
+	// still active, so that one of several concurrent refreshes wins. It
+	// returns ErrNotFound if the token is missing or already revoked.
+	Rotate(ctx context.Context, id string, at time.Time, replacedBy string) error
+	// RevokeFamily revokes every token descended from the same login.
+	RevokeFamily(ctx context.Context, familyID string, at time.Time) error
+	// RevokeAllForUser signs the user out of every device.
+	RevokeAllForUser(ctx context.Context, userID string, at time.Time) error
+}
+
+// RevokedTokenRepository is a deny list of access token IDs (jti). Entries
+// only matter until the token's own expiry.
+type RevokedTokenRepository interface {
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+type ActionTokenRepository interface {
+	Create(ctx context.Context, token *models.ActionToken) error
+	// Consume atomically marks the token used and returns it. A token that
+	// does not exist or was already used yields ErrNotFound.
+	Consume(ctx context.Context, id string, at time.Time) (*models.ActionToken, error)
+}
+
+// Store bundles the repositories of one backend.
+type Store struct {
+	Users           UserRepository
+	Activities      ActivityRepository
+	Rollups         ActivityRollupRepository
+	HealthRecords   HealthRecordRepository
+	ChatMessages    ChatMessageRepository
+	ChatSessions    ChatSessionRepository
+	ChatFeedback    ChatFeedbackRepository
+	Goals           GoalRepository
+	Achievements    AchievementRepository
+	HeartRateAlerts HeartRateAlertRepository
+	RefreshTokens   RefreshTokenRepository
+	RevokedTokens   RevokedTokenRepository
+	ActionTokens    ActionTokenRepository
+}
@@ -0,0 +1,36 @@
+package sqlrepo
+
+import (
+	"context"
+
+	"orchestrator-service/models"
+)
+
+type achievementRepository struct {
+	*conn
+}
+
+func (r *achievementRepository) Award(ctx context.Context, achievement *models.Achievement) error {
+	_, err := r.exec(ctx, `INSERT INTO achievements (user_id, badge_id, earned_at) VALUES (?, ?, ?)
+		ON CONFLICT (user_id, badge_id) DO NOTHING`,
+		achievement.UserID, achievement.BadgeID, achievement.EarnedAt)
+	return err
+}
+
+func (r *achievementRepository) List(ctx context.Context, userID string) ([]models.Achievement, error) {
+	rows, err := r.query(ctx, `SELECT user_id, badge_id, earned_at FROM achievements WHERE user_id = ? ORDER BY earned_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var achievements []models.Achievement
+	for rows.Next() {
+		var achievement models.Achievement
+		if err := rows.Scan(&achievement.UserID, &achievement.BadgeID, &achievement.EarnedAt); err != nil {
+			return nil, err
+		}
+		achievements = append(achievements, achievement)
+	}
+	return achievements, rows.Err()
+}
@@ -0,0 +1,89 @@
+package sqlrepo
+
+import (
+	"context"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+)
+
+type activityRepository struct {
+	*conn
+}
+
+const activityColumns = `id, user_id, type, value, unit, description, date, created_at`
+
+func (r *activityRepository) Create(ctx context.Context, activity *models.Activity) error {
+	_, err := r.exec(ctx, `INSERT INTO activities (`+activityColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		activity.ID, activity.UserID, activity.Type, activity.Value, activity.Unit,
+		activity.Description, activity.Date, activity.CreatedAt)
+	return err
+}
+
+func (r *activityRepository) Get(ctx context.Context, id string) (*models.Activity, error) {
+	row := r.queryRow(ctx, `SELECT `+activityColumns+` FROM activities WHERE id = ?`, id)
+	return scanActivity(row)
+}
+
+func (r *activityRepository) Update(ctx context.Context, activity *models.Activity) error {
+	return r.update(ctx, `UPDATE activities SET type = ?, value = ?, unit = ?, description = ?, date = ? WHERE id = ?`,
+		activity.Type, activity.Value, activity.Unit, activity.Description, activity.Date, activity.ID)
+}
+
+func (r *activityRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.exec(ctx, `DELETE FROM activities WHERE id = ?`, id)
+	return err
+}
+
+func (r *activityRepository) List(ctx context.Context, filter repository.ActivityFilter) ([]models.Activity, error) {
+	query := `SELECT ` + activityColumns + ` FROM activities WHERE user_id = ?`
+	args := []any{filter.UserID}
+	if filter.Type != "" {
+		query += ` AND type = ?`
+		args = append(args, filter.Type)
+	}
+	if !filter.From.IsZero() {
+		query += ` AND date >= ?`
+		args = append(args, filter.From)
+	}
+	if !filter.To.IsZero() {
+		query += ` AND date < ?`
+		args = append(args, filter.To)
+	}
+	if filter.After != nil {
+		condition, cursorArgs := afterCursor("date", false, filter.After)
+		query += condition
+		args = append(args, cursorArgs...)
+	}
+	query += ` ORDER BY date, id`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var activities []models.Activity
+	for rows.Next() {
+		activity, err := scanActivity(rows)
+		if err != nil {
+			return nil, err
+		}
+		activities = append(activities, *activity)
+	}
+	return activities, rows.Err()
+}
+
+func scanActivity(row rowScanner) (*models.Activity, error) {
+	var activity models.Activity
+	err := row.Scan(&activity.ID, &activity.UserID, &activity.Type, &activity.Value,
+		&activity.Unit, &activity.Description, &activity.Date, &activity.CreatedAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &activity, nil
+}
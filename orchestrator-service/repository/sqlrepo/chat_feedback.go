@@ -0,0 +1,82 @@
+package sqlrepo
+
+import (
+	"context"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+)
+
+type chatFeedbackRepository struct {
+	*conn
+}
+
+const chatFeedbackColumns = `message_id, user_id, session_id, rating, reason, comment, created_at, updated_at`
+
+func (r *chatFeedbackRepository) Save(ctx context.Context, feedback *models.ChatFeedback) error {
+	_, err := r.exec(ctx, `INSERT INTO chat_feedback (`+chatFeedbackColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (message_id) DO UPDATE SET
+			rating = excluded.rating,
+			reason = excluded.reason,
+			comment = excluded.comment,
+			created_at = excluded.created_at,
+			updated_at = excluded.updated_at`,
+		feedback.MessageID, feedback.UserID, feedback.SessionID, feedback.Rating, feedback.Reason,
+		feedback.Comment, feedback.CreatedAt, feedback.UpdatedAt)
+	return err
+}
+
+func (r *chatFeedbackRepository) Get(ctx context.Context, messageID string) (*models.ChatFeedback, error) {
+	row := r.queryRow(ctx, `SELECT `+chatFeedbackColumns+` FROM chat_feedback WHERE message_id = ?`, messageID)
+	return scanChatFeedback(row)
+}
+
+func (r *chatFeedbackRepository) List(ctx context.Context, filter repository.ChatFeedbackFilter) ([]models.ChatFeedback, error) {
+	query := `SELECT ` + chatFeedbackColumns + ` FROM chat_feedback WHERE 1 = 1`
+	var args []any
+	if filter.UserID != "" {
+		query += ` AND user_id = ?`
+		args = append(args, filter.UserID)
+	}
+	if !filter.From.IsZero() {
+		query += ` AND created_at >= ?`
+		args = append(args, filter.From)
+	}
+	if !filter.To.IsZero() {
+		query += ` AND created_at < ?`
+		args = append(args, filter.To)
+	}
+	query += ` ORDER BY created_at`
+
+	rows, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var feedback []models.ChatFeedback
+	for rows.Next() {
+		f, err := scanChatFeedback(rows)
+		if err != nil {
+			return nil, err
+		}
+		feedback = append(feedback, *f)
+	}
+	return feedback, rows.Err()
+}
+
+func (r *chatFeedbackRepository) DeleteBySession(ctx context.Context, userID, sessionID string) error {
+	_, err := r.exec(ctx, `DELETE FROM chat_feedback WHERE user_id = ? AND session_id = ?`, userID, sessionID)
+	return err
+}
+
+func scanChatFeedback(row rowScanner) (*models.ChatFeedback, error) {
+	var feedback models.ChatFeedback
+	err := row.Scan(&feedback.MessageID, &feedback.UserID, &feedback.SessionID, &feedback.Rating,
+		&feedback.Reason, &feedback.Comment, &feedback.CreatedAt, &feedback.UpdatedAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &feedback, nil
+}
@@ -0,0 +1,134 @@
+package sqlrepo
+
+import (
+	"context"
+	"strings"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+	"orchestrator-service/search"
+)
+
+type chatMessageRepository struct {
+	*conn
+}
+
+const chatMessageColumns = `id, user_id, text, sender, sent_at, session_id, flag, flag_rule`
+
+func (r *chatMessageRepository) Create(ctx context.Context, message *models.ChatMessage) error {
+	return r.inTx(ctx, func(tx *conn) error {
+		_, err := tx.exec(ctx, `INSERT INTO chat_messages (`+chatMessageColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			message.ID, message.UserID, message.Text, message.Sender, message.Timestamp, message.SessionID,
+			message.Flag, message.FlagRule)
+		if err != nil {
+			return err
+		}
+
+		for _, term := range search.Terms(message.Text) {
+			_, err := tx.exec(ctx, `INSERT INTO chat_message_terms (message_id, user_id, term) VALUES (?, ?, ?)`,
+				message.ID, message.UserID, term)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *chatMessageRepository) Get(ctx context.Context, id string) (*models.ChatMessage, error) {
+	row := r.queryRow(ctx, `SELECT `+chatMessageColumns+` FROM chat_messages WHERE id = ?`, id)
+	return scanChatMessage(row)
+}
+
+func (r *chatMessageRepository) List(ctx context.Context, filter repository.ChatMessageFilter) ([]models.ChatMessage, error) {
+	query := `SELECT ` + chatMessageColumns + ` FROM chat_messages WHERE user_id = ?`
+	args := []any{filter.UserID}
+	if filter.SessionID != "" {
+		query += ` AND session_id = ?`
+		args = append(args, filter.SessionID)
+	}
+	if filter.After != nil {
+		condition, cursorArgs := afterCursor("sent_at", true, filter.After)
+		query += condition
+		args = append(args, cursorArgs...)
+	}
+	query += ` ORDER BY sent_at DESC, id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []models.ChatMessage
+	for rows.Next() {
+		message, err := scanChatMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, *message)
+	}
+	return messages, rows.Err()
+}
+
+func scanChatMessage(row rowScanner) (*models.ChatMessage, error) {
+	var message models.ChatMessage
+	err := row.Scan(&message.ID, &message.UserID, &message.Text, &message.Sender,
+		&message.Timestamp, &message.SessionID, &message.Flag, &message.FlagRule)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &message, nil
+}
+
+func (r *chatMessageRepository) DeleteBySession(ctx context.Context, userID, sessionID string) error {
+	return r.inTx(ctx, func(tx *conn) error {
+		_, err := tx.exec(ctx, `DELETE FROM chat_message_terms WHERE message_id IN (
+			SELECT id FROM chat_messages WHERE user_id = ? AND session_id = ?)`, userID, sessionID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.exec(ctx, `DELETE FROM chat_messages WHERE user_id = ? AND session_id = ?`, userID, sessionID)
+		return err
+	})
+}
+
+func (r *chatMessageRepository) Search(ctx context.Context, filter repository.ChatSearchFilter) ([]models.ChatMessage, error) {
+	if len(filter.Terms) == 0 {
+		return nil, nil
+	}
+
+	query := `SELECT ` + chatMessageColumns + ` FROM chat_messages WHERE user_id = ? AND id IN (
+		SELECT message_id FROM chat_message_terms WHERE user_id = ? AND term IN (?` +
+		strings.Repeat(`, ?`, len(filter.Terms)-1) + `))
+		ORDER BY sent_at DESC, id DESC`
+	args := []any{filter.UserID, filter.UserID}
+	for _, term := range filter.Terms {
+		args = append(args, term)
+	}
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []models.ChatMessage
+	for rows.Next() {
+		message, err := scanChatMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, *message)
+	}
+	return messages, rows.Err()
+}
@@ -0,0 +1,80 @@
+package sqlrepo
+
+import (
+	"context"
+	"time"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+)
+
+type chatSessionRepository struct {
+	*conn
+}
+
+const chatSessionColumns = `id, user_id, title, message_count, created_at, updated_at`
+
+func (r *chatSessionRepository) Create(ctx context.Context, session *models.ChatSession) error {
+	_, err := r.exec(ctx, `INSERT INTO chat_sessions (`+chatSessionColumns+`) VALUES (?, ?, ?, ?, ?, ?)`,
+		session.ID, session.UserID, session.Title, session.MessageCount, session.CreatedAt, session.UpdatedAt)
+	return err
+}
+
+func (r *chatSessionRepository) Get(ctx context.Context, id string) (*models.ChatSession, error) {
+	row := r.queryRow(ctx, `SELECT `+chatSessionColumns+` FROM chat_sessions WHERE id = ?`, id)
+	return scanChatSession(row)
+}
+
+func (r *chatSessionRepository) UpdateTitle(ctx context.Context, id, title string) error {
+	return r.update(ctx, `UPDATE chat_sessions SET title = ? WHERE id = ?`, title, id)
+}
+
+func (r *chatSessionRepository) AddMessages(ctx context.Context, id string, n int, at time.Time) error {
+	return r.update(ctx, `UPDATE chat_sessions SET message_count = message_count + ?, updated_at = ? WHERE id = ?`, n, at, id)
+}
+
+func (r *chatSessionRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.exec(ctx, `DELETE FROM chat_sessions WHERE id = ?`, id)
+	return err
+}
+
+func (r *chatSessionRepository) List(ctx context.Context, filter repository.ChatSessionFilter) ([]models.ChatSession, error) {
+	query := `SELECT ` + chatSessionColumns + ` FROM chat_sessions WHERE user_id = ?`
+	args := []any{filter.UserID}
+	if filter.After != nil {
+		condition, cursorArgs := afterCursor("updated_at", true, filter.After)
+		query += condition
+		args = append(args, cursorArgs...)
+	}
+	query += ` ORDER BY updated_at DESC, id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []models.ChatSession
+	for rows.Next() {
+		session, err := scanChatSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, rows.Err()
+}
+
+func scanChatSession(row rowScanner) (*models.ChatSession, error) {
+	var session models.ChatSession
+	err := row.Scan(&session.ID, &session.UserID, &session.Title, &session.MessageCount,
+		&session.CreatedAt, &session.UpdatedAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &session, nil
+}
@@ -0,0 +1,94 @@
+package sqlrepo
+
+import (
+	"context"
+
+	"orchestrator-service/models"
+)
+
+type goalRepository struct {
+	*conn
+}
+
+const (
+	goalColumns       = `id, user_id, type, period, target, unit, start_date, end_date, created_at, updated_at`
+	goalChangeColumns = `id, goal_id, user_id, action, type, period, target, unit, start_date, end_date, changed_at`
+)
+
+func (r *goalRepository) Create(ctx context.Context, goal *models.Goal) error {
+	_, err := r.exec(ctx, `INSERT INTO goals (`+goalColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		goal.ID, goal.UserID, goal.Type, goal.Period, goal.Target, goal.Unit,
+		goal.StartDate, goal.EndDate, goal.CreatedAt, goal.UpdatedAt)
+	return err
+}
+
+func (r *goalRepository) Get(ctx context.Context, id string) (*models.Goal, error) {
+	row := r.queryRow(ctx, `SELECT `+goalColumns+` FROM goals WHERE id = ?`, id)
+	return scanGoal(row)
+}
+
+func (r *goalRepository) Update(ctx context.Context, goal *models.Goal) error {
+	return r.update(ctx, `UPDATE goals SET type = ?, period = ?, target = ?, unit = ?, start_date = ?, end_date = ?, updated_at = ?
+		WHERE id = ?`,
+		goal.Type, goal.Period, goal.Target, goal.Unit, goal.StartDate, goal.EndDate, goal.UpdatedAt, goal.ID)
+}
+
+func (r *goalRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.exec(ctx, `DELETE FROM goals WHERE id = ?`, id)
+	return err
+}
+
+func (r *goalRepository) List(ctx context.Context, userID string) ([]models.Goal, error) {
+	rows, err := r.query(ctx, `SELECT `+goalColumns+` FROM goals WHERE user_id = ? ORDER BY start_date, id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var goals []models.Goal
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			return nil, err
+		}
+		goals = append(goals, *goal)
+	}
+	return goals, rows.Err()
+}
+
+func (r *goalRepository) AddChange(ctx context.Context, change *models.GoalChange) error {
+	_, err := r.exec(ctx, `INSERT INTO goal_changes (`+goalChangeColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		change.ID, change.GoalID, change.UserID, change.Action, change.Type, change.Period, change.Target,
+		change.Unit, change.StartDate, change.EndDate, change.ChangedAt)
+	return err
+}
+
+func (r *goalRepository) Changes(ctx context.Context, goalID string) ([]models.GoalChange, error) {
+	rows, err := r.query(ctx, `SELECT `+goalChangeColumns+` FROM goal_changes WHERE goal_id = ? ORDER BY changed_at, id`, goalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []models.GoalChange
+	for rows.Next() {
+		var change models.GoalChange
+		err := rows.Scan(&change.ID, &change.GoalID, &change.UserID, &change.Action, &change.Type, &change.Period,
+			&change.Target, &change.Unit, &change.StartDate, &change.EndDate, &change.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+func scanGoal(row rowScanner) (*models.Goal, error) {
+	var goal models.Goal
+	err := row.Scan(&goal.ID, &goal.UserID, &goal.Type, &goal.Period, &goal.Target, &goal.Unit,
+		&goal.StartDate, &goal.EndDate, &goal.CreatedAt, &goal.UpdatedAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &goal, nil
+}
@@ -0,0 +1,72 @@
+package sqlrepo
+
+import (
+	"context"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+)
+
+type healthRecordRepository struct {
+	*conn
+}
+
+const healthRecordColumns = `id, user_id, title, date, doctor, type, status, description, file_url, created_at`
+
+func (r *healthRecordRepository) Create(ctx context.Context, record *models.HealthRecord) error {
+	_, err := r.exec(ctx, `INSERT INTO health_records (`+healthRecordColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		record.ID, record.UserID, record.Title, record.Date, record.Doctor, record.Type,
+		record.Status, record.Description, record.FileURL, record.CreatedAt)
+	return err
+}
+
+func (r *healthRecordRepository) Get(ctx context.Context, id string) (*models.HealthRecord, error) {
+	row := r.queryRow(ctx, `SELECT `+healthRecordColumns+` FROM health_records WHERE id = ?`, id)
+	return scanHealthRecord(row)
+}
+
+func (r *healthRecordRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.exec(ctx, `DELETE FROM health_records WHERE id = ?`, id)
+	return err
+}
+
+func (r *healthRecordRepository) List(ctx context.Context, filter repository.HealthRecordFilter) ([]models.HealthRecord, error) {
+	query := `SELECT ` + healthRecordColumns + ` FROM health_records WHERE user_id = ?`
+	args := []any{filter.UserID}
+	if filter.After != nil {
+		condition, cursorArgs := afterCursor("created_at", false, filter.After)
+		query += condition
+		args = append(args, cursorArgs...)
+	}
+	query += ` ORDER BY created_at, id`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []models.HealthRecord
+	for rows.Next() {
+		record, err := scanHealthRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+	return records, rows.Err()
+}
+
+func scanHealthRecord(row rowScanner) (*models.HealthRecord, error) {
+	var record models.HealthRecord
+	err := row.Scan(&record.ID, &record.UserID, &record.Title, &record.Date, &record.Doctor,
+		&record.Type, &record.Status, &record.Description, &record.FileURL, &record.CreatedAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &record, nil
+}
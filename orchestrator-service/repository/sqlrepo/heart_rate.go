@@ -0,0 +1,110 @@
+package sqlrepo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+)
+
+type heartRateAlertRepository struct {
+	*conn
+}
+
+const heartRateAlertColumns = `id, user_id, kind, threshold, baseline, peak, readings, started_at, ended_at, status,
+	created_at, updated_at, acknowledged_at, notified_at`
+
+func (r *heartRateAlertRepository) Create(ctx context.Context, alert *models.HeartRateAlert) error {
+	_, err := r.exec(ctx, `INSERT INTO heart_rate_alerts (`+heartRateAlertColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		alert.ID, alert.UserID, alert.Kind, alert.Threshold, alert.Baseline, alert.Peak, alert.Readings,
+		alert.Start, alert.End, alert.Status, alert.CreatedAt, alert.UpdatedAt,
+		nullTimePtr(alert.AcknowledgedAt), nullTimePtr(alert.NotifiedAt))
+	return err
+}
+
+func (r *heartRateAlertRepository) Get(ctx context.Context, id string) (*models.HeartRateAlert, error) {
+	row := r.queryRow(ctx, `SELECT `+heartRateAlertColumns+` FROM heart_rate_alerts WHERE id = ?`, id)
+	return scanHeartRateAlert(row)
+}
+
+func (r *heartRateAlertRepository) Update(ctx context.Context, alert *models.HeartRateAlert) error {
+	return r.update(ctx, `UPDATE heart_rate_alerts SET threshold = ?, baseline = ?, peak = ?, readings = ?,
+		started_at = ?, ended_at = ?, status = ?, updated_at = ?, acknowledged_at = ?, notified_at = ?
+		WHERE id = ?`,
+		alert.Threshold, alert.Baseline, alert.Peak, alert.Readings, alert.Start, alert.End, alert.Status,
+		alert.UpdatedAt, nullTimePtr(alert.AcknowledgedAt), nullTimePtr(alert.NotifiedAt), alert.ID)
+}
+
+func (r *heartRateAlertRepository) List(ctx context.Context, filter repository.HeartRateAlertFilter) ([]models.HeartRateAlert, error) {
+	query := `SELECT ` + heartRateAlertColumns + ` FROM heart_rate_alerts WHERE user_id = ?`
+	args := []any{filter.UserID}
+	if filter.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+	if !filter.From.IsZero() {
+		query += ` AND started_at >= ?`
+		args = append(args, filter.From)
+	}
+	if filter.After != nil {
+		condition, cursorArgs := afterCursor("started_at", true, filter.After)
+		query += condition
+		args = append(args, cursorArgs...)
+	}
+	query += ` ORDER BY started_at DESC, id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []models.HeartRateAlert
+	for rows.Next() {
+		alert, err := scanHeartRateAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, *alert)
+	}
+	return alerts, rows.Err()
+}
+
+func scanHeartRateAlert(row rowScanner) (*models.HeartRateAlert, error) {
+	var (
+		alert                      models.HeartRateAlert
+		acknowledgedAt, notifiedAt sql.NullTime
+	)
+	err := row.Scan(&alert.ID, &alert.UserID, &alert.Kind, &alert.Threshold, &alert.Baseline, &alert.Peak,
+		&alert.Readings, &alert.Start, &alert.End, &alert.Status, &alert.CreatedAt, &alert.UpdatedAt,
+		&acknowledgedAt, &notifiedAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	alert.AcknowledgedAt = timePtr(acknowledgedAt)
+	alert.NotifiedAt = timePtr(notifiedAt)
+	return &alert, nil
+}
+
+// nullTimePtr stores a nil time as NULL.
+func nullTimePtr(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return nullTime(*t)
+}
+
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
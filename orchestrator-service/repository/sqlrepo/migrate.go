@@ -0,0 +1,142 @@
+package sqlrepo
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"orchestrator-service/search"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// Migrate applies the migrations under migrations/<dialect> that have not
+// run yet. Files are named NNNN_description.sql and run in order, each in
+// its own transaction.
+func Migrate(ctx context.Context, db *sql.DB, dialect Dialect) error {
+	c := &conn{db: db, dialect: dialect}
+
+	_, err := c.exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
+
+	applied := make(map[int]bool)
+	rows, err := c.query(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[version] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	dir := path.Join("migrations", string(dialect))
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for _, entry := range entries {
+		name := entry.Name()
+		version, err := strconv.Atoi(strings.SplitN(name, "_", 2)[0])
+		if err != nil {
+			return fmt.Errorf("invalid migration name %q", name)
+		}
+		if applied[version] {
+			continue
+		}
+
+		script, err := fs.ReadFile(migrationFiles, path.Join(dir, name))
+		if err != nil {
+			return err
+		}
+
+		if err := applyMigration(ctx, c, version, string(script)); err != nil {
+			return fmt.Errorf("migration %s: %w", name, err)
+		}
+		log.Printf("Applied migration %s", name)
+	}
+
+	return nil
+}
+
+func applyMigration(ctx context.Context, c *conn, version int, script string) error {
+	return c.inTx(ctx, func(tx *conn) error {
+		// Scripts go through unchanged; rebinding would touch literal "?"s
+		if _, err := tx.db.ExecContext(ctx, script); err != nil {
+			return err
+		}
+
+		if backfill, ok := backfills[version]; ok {
+			if err := backfill(ctx, tx); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.exec(ctx, `INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`, version, time.Now())
+		return err
+	})
+}
+
+// backfills fill in data a migration cannot compute in SQL. Each runs in
+// the same transaction as the migration with that version.
+var backfills = map[int]func(ctx context.Context, tx *conn) error{
+	8: indexChatMessages,
+}
+
+// indexChatMessages builds the search index for messages stored before
+// chat search existed.
+func indexChatMessages(ctx context.Context, tx *conn) error {
+	rows, err := tx.query(ctx, `SELECT id, user_id, text FROM chat_messages`)
+	if err != nil {
+		return err
+	}
+
+	type message struct{ id, userID, text string }
+	var messages []message
+	for rows.Next() {
+		var m message
+		if err := rows.Scan(&m.id, &m.userID, &m.text); err != nil {
+			rows.Close()
+			return err
+		}
+		messages = append(messages, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, m := range messages {
+		for _, term := range search.Terms(m.text) {
+			_, err := tx.exec(ctx, `INSERT INTO chat_message_terms (message_id, user_id, term) VALUES (?, ?, ?)`,
+				m.id, m.userID, term)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+CREATE TABLE goals (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT NOT NULL,
+    type       TEXT NOT NULL,
+    period     TEXT NOT NULL,
+    target     DOUBLE PRECISION NOT NULL,
+    unit       TEXT NOT NULL,
+    start_date TEXT NOT NULL,
+    end_date   TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX goals_user ON goals (user_id, start_date);
+
+CREATE TABLE goal_changes (
+    id         TEXT PRIMARY KEY,
+    goal_id    TEXT NOT NULL,
+    user_id    TEXT NOT NULL,
+    action     TEXT NOT NULL,
+    type       TEXT NOT NULL,
+    period     TEXT NOT NULL,
+    target     DOUBLE PRECISION NOT NULL,
+    unit       TEXT NOT NULL,
+    start_date TEXT NOT NULL,
+    end_date   TEXT NOT NULL DEFAULT '',
+    changed_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX goal_changes_goal ON goal_changes (goal_id, changed_at);
@@ -0,0 +1,6 @@
+CREATE TABLE achievements (
+    user_id   TEXT NOT NULL,
+    badge_id  TEXT NOT NULL,
+    earned_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, badge_id)
+);
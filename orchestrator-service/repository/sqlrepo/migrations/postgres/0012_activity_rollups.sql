@@ -0,0 +1,15 @@
+CREATE TABLE activity_rollups (
+    user_id    TEXT NOT NULL,
+    date       TEXT NOT NULL,
+    type       TEXT NOT NULL,
+    unit       TEXT NOT NULL,
+    count      INTEGER NOT NULL,
+    sum        DOUBLE PRECISION NOT NULL,
+    min        DOUBLE PRECISION NOT NULL,
+    max        DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, date, type, unit)
+);
+
+-- Rollups are built on first use
+ALTER TABLE users ADD COLUMN rollup_zone TEXT NOT NULL DEFAULT '';
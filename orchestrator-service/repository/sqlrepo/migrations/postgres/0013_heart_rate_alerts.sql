@@ -0,0 +1,18 @@
+CREATE TABLE heart_rate_alerts (
+    id              TEXT PRIMARY KEY,
+    user_id         TEXT NOT NULL,
+    kind            TEXT NOT NULL,
+    threshold       DOUBLE PRECISION NOT NULL,
+    baseline        DOUBLE PRECISION NOT NULL,
+    peak            DOUBLE PRECISION NOT NULL,
+    readings        INTEGER NOT NULL,
+    started_at      TIMESTAMPTZ NOT NULL,
+    ended_at        TIMESTAMPTZ NOT NULL,
+    status          TEXT NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL,
+    acknowledged_at TIMESTAMPTZ,
+    notified_at     TIMESTAMPTZ
+);
+
+CREATE INDEX heart_rate_alerts_user ON heart_rate_alerts (user_id, started_at);
@@ -0,0 +1,4 @@
+-- Feedback stats are per user, and feedback goes with its chat session
+DROP INDEX chat_feedback_created;
+CREATE INDEX chat_feedback_user_created ON chat_feedback (user_id, created_at);
+CREATE INDEX chat_feedback_session ON chat_feedback (user_id, session_id);
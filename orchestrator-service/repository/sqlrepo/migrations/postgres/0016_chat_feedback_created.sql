@@ -0,0 +1,2 @@
+-- Operators' feedback stats span every user
+CREATE INDEX chat_feedback_created ON chat_feedback (created_at);
@@ -0,0 +1,60 @@
+CREATE TABLE users (
+    id            TEXT PRIMARY KEY,
+    email         TEXT NOT NULL UNIQUE,
+    password      TEXT NOT NULL DEFAULT '',
+    full_name     TEXT NOT NULL DEFAULT '',
+    date_of_birth TIMESTAMP NOT NULL,
+    gender        TEXT NOT NULL DEFAULT '',
+    height        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    weight        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    blood_type    TEXT NOT NULL DEFAULT '',
+    allergies     TEXT NOT NULL DEFAULT '',
+    medications   TEXT NOT NULL DEFAULT '',
+    conditions    TEXT NOT NULL DEFAULT '',
+    profile_image TEXT NOT NULL DEFAULT '',
+    settings      TEXT NOT NULL DEFAULT '{}',
+    provider      TEXT NOT NULL DEFAULT '',
+    google_id     TEXT NOT NULL DEFAULT '',
+    created_at    TIMESTAMP NOT NULL,
+    updated_at    TIMESTAMP NOT NULL
+);
+
+CREATE TABLE activities (
+    id          TEXT PRIMARY KEY,
+    user_id     TEXT NOT NULL,
+    type        TEXT NOT NULL,
+    value       DOUBLE PRECISION NOT NULL,
+    unit        TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    date        TIMESTAMP NOT NULL,
+    created_at  TIMESTAMP NOT NULL
+);
+
+CREATE INDEX activities_user_date ON activities (user_id, date);
+
+CREATE TABLE health_records (
+    id          TEXT PRIMARY KEY,
+    user_id     TEXT NOT NULL,
+    title       TEXT NOT NULL DEFAULT '',
+    date        TIMESTAMP NOT NULL,
+    doctor      TEXT NOT NULL DEFAULT '',
+    type        TEXT NOT NULL DEFAULT '',
+    status      TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    file_url    TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL
+);
+
+CREATE INDEX health_records_user ON health_records (user_id, created_at);
+
+CREATE TABLE chat_messages (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT NOT NULL,
+    text       TEXT NOT NULL,
+    sender     TEXT NOT NULL,
+    sent_at    TIMESTAMP NOT NULL,
+    session_id TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX chat_messages_user_sent ON chat_messages (user_id, sent_at);
+CREATE INDEX chat_messages_session_sent ON chat_messages (user_id, session_id, sent_at);
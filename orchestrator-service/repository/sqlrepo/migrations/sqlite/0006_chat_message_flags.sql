@@ -0,0 +1,2 @@
+ALTER TABLE chat_messages ADD COLUMN flag TEXT NOT NULL DEFAULT '';
+ALTER TABLE chat_messages ADD COLUMN flag_rule TEXT NOT NULL DEFAULT '';
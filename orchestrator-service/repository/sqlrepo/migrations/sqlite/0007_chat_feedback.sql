@@ -0,0 +1,12 @@
+CREATE TABLE chat_feedback (
+    message_id TEXT PRIMARY KEY,
+    user_id    TEXT NOT NULL,
+    session_id TEXT NOT NULL DEFAULT '',
+    rating     TEXT NOT NULL,
+    reason     TEXT NOT NULL DEFAULT '',
+    comment    TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX chat_feedback_created ON chat_feedback (created_at);
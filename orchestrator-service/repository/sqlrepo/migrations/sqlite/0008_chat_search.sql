@@ -0,0 +1,9 @@
+-- Inverted index for chat search, one row per distinct term of a message
+CREATE TABLE chat_message_terms (
+    message_id TEXT NOT NULL,
+    user_id    TEXT NOT NULL,
+    term       TEXT NOT NULL,
+    PRIMARY KEY (message_id, term)
+);
+
+CREATE INDEX chat_message_terms_user_term ON chat_message_terms (user_id, term);
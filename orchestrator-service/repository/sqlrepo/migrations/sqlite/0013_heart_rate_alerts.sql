@@ -0,0 +1,18 @@
+CREATE TABLE heart_rate_alerts (
+    id              TEXT PRIMARY KEY,
+    user_id         TEXT NOT NULL,
+    kind            TEXT NOT NULL,
+    threshold       DOUBLE PRECISION NOT NULL,
+    baseline        DOUBLE PRECISION NOT NULL,
+    peak            DOUBLE PRECISION NOT NULL,
+    readings        INTEGER NOT NULL,
+    started_at      TIMESTAMP NOT NULL,
+    ended_at        TIMESTAMP NOT NULL,
+    status          TEXT NOT NULL,
+    created_at      TIMESTAMP NOT NULL,
+    updated_at      TIMESTAMP NOT NULL,
+    acknowledged_at TIMESTAMP,
+    notified_at     TIMESTAMP
+);
+
+CREATE INDEX heart_rate_alerts_user ON heart_rate_alerts (user_id, started_at);
@@ -0,0 +1,9 @@
+-- The zone rollups were built in, kept apart from users so that rebuilding
+-- rollups never writes the user row
+CREATE TABLE activity_rollup_zones (
+    user_id TEXT PRIMARY KEY,
+    zone    TEXT NOT NULL
+);
+
+-- Rollups built so far are rebuilt on first use
+ALTER TABLE users DROP COLUMN rollup_zone;
@@ -0,0 +1,87 @@
+package sqlrepo
+
+import (
+	"context"
+	"database/sql"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+)
+
+type activityRollupRepository struct {
+	*conn
+}
+
+const activityRollupColumns = `user_id, date, type, unit, count, sum, min, max, updated_at`
+
+func (r *activityRollupRepository) ReplaceDay(ctx context.Context, userID, date string, rollups []models.ActivityRollup) error {
+	return r.inTx(ctx, func(tx *conn) error {
+		if _, err := tx.exec(ctx, `DELETE FROM activity_rollups WHERE user_id = ? AND date = ?`, userID, date); err != nil {
+			return err
+		}
+		for _, rollup := range rollups {
+			_, err := tx.exec(ctx, `INSERT INTO activity_rollups (`+activityRollupColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				userID, date, rollup.Type, rollup.Unit, rollup.Count, rollup.Sum, rollup.Min, rollup.Max, rollup.UpdatedAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *activityRollupRepository) List(ctx context.Context, filter repository.ActivityRollupFilter) ([]models.ActivityRollup, error) {
+	query := `SELECT ` + activityRollupColumns + ` FROM activity_rollups WHERE user_id = ?`
+	args := []any{filter.UserID}
+	if filter.From != "" {
+		query += ` AND date >= ?`
+		args = append(args, filter.From)
+	}
+	if filter.To != "" {
+		query += ` AND date <= ?`
+		args = append(args, filter.To)
+	}
+	if filter.Type != "" {
+		query += ` AND type = ?`
+		args = append(args, filter.Type)
+	}
+	query += ` ORDER BY date, type, unit`
+
+	rows, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rollups []models.ActivityRollup
+	for rows.Next() {
+		var rollup models.ActivityRollup
+		err := rows.Scan(&rollup.UserID, &rollup.Date, &rollup.Type, &rollup.Unit, &rollup.Count,
+			&rollup.Sum, &rollup.Min, &rollup.Max, &rollup.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		rollups = append(rollups, rollup)
+	}
+	return rollups, rows.Err()
+}
+
+func (r *activityRollupRepository) DeleteByUser(ctx context.Context, userID string) error {
+	_, err := r.exec(ctx, `DELETE FROM activity_rollups WHERE user_id = ?`, userID)
+	return err
+}
+
+func (r *activityRollupRepository) Zone(ctx context.Context, userID string) (string, error) {
+	var zone string
+	err := r.queryRow(ctx, `SELECT zone FROM activity_rollup_zones WHERE user_id = ?`, userID).Scan(&zone)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return zone, err
+}
+
+func (r *activityRollupRepository) SetZone(ctx context.Context, userID, zone string) error {
+	_, err := r.exec(ctx, `INSERT INTO activity_rollup_zones (user_id, zone) VALUES (?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET zone = excluded.zone`, userID, zone)
+	return err
+}
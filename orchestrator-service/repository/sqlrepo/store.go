@@ -0,0 +1,191 @@
+// Package sqlrepo implements the repository interfaces on PostgreSQL or
+// SQLite through database/sql.
+package sqlrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"orchestrator-service/repository"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+// Dialect names a supported SQL database.
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+func (d Dialect) driverName() string {
+	if d == Postgres {
+		return "pgx"
+	}
+	return "sqlite"
+}
+
+// Open connects to the database and applies any pending migrations.
+func Open(ctx context.Context, dialect Dialect, dsn string) (*sql.DB, error) {
+	if dialect != Postgres && dialect != SQLite {
+		return nil, fmt.Errorf("unsupported SQL dialect %q", dialect)
+	}
+
+	db, err := sql.Open(dialect.driverName(), dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows a single writer; serialize access instead of failing
+	// with "database is locked".
+	if dialect == SQLite {
+		db.SetMaxOpenConns(1)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err := Migrate(ctx, db, dialect); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func NewStore(db *sql.DB, dialect Dialect) *repository.Store {
+	c := &conn{db: db, dialect: dialect}
+	return &repository.Store{
+		Users:           &userRepository{c},
+		Activities:      &activityRepository{c},
+		Rollups:         &activityRollupRepository{c},
+		HealthRecords:   &healthRecordRepository{c},
+		ChatMessages:    &chatMessageRepository{c},
+		ChatSessions:    &chatSessionRepository{c},
+		ChatFeedback:    &chatFeedbackRepository{c},
+		Goals:           &goalRepository{c},
+		Achievements:    &achievementRepository{c},
+		HeartRateAlerts: &heartRateAlertRepository{c},
+		RefreshTokens:   &refreshTokenRepository{c},
+		RevokedTokens:   &revokedTokenRepository{c},
+		ActionTokens:    &actionTokenRepository{c},
+	}
+}
+
+// conn wraps *sql.DB so queries can be written once with "?" placeholders
+// and with every time stored in UTC, which keeps SQLite's textual
+// timestamps comparable.
+type conn struct {
+	db      dbtx
+	dialect Dialect
+}
+
+// dbtx is satisfied by both *sql.DB and *sql.Tx.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// inTx runs fn against a conn bound to a transaction, committing if fn
+// succeeds. Nested calls join the outer transaction.
+func (c *conn) inTx(ctx context.Context, fn func(tx *conn) error) error {
+	db, ok := c.db.(*sql.DB)
+	if !ok {
+		return fn(c)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(&conn{db: tx, dialect: c.dialect}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (c *conn) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return c.db.ExecContext(ctx, c.rebind(query), normalizeArgs(args)...)
+}
+
+// update runs a single-row UPDATE, reporting ErrNotFound if no row matched.
+func (c *conn) update(ctx context.Context, query string, args ...any) error {
+	res, err := c.exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (c *conn) query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return c.db.QueryContext(ctx, c.rebind(query), normalizeArgs(args)...)
+}
+
+func (c *conn) queryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	return c.db.QueryRowContext(ctx, c.rebind(query), normalizeArgs(args)...)
+}
+
+// rebind turns "?" placeholders into PostgreSQL's "$n" form.
+func (c *conn) rebind(query string) string {
+	if c.dialect != Postgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func normalizeArgs(args []any) []any {
+	for i, arg := range args {
+		if t, ok := arg.(time.Time); ok {
+			args[i] = t.UTC()
+		}
+	}
+	return args
+}
+
+// afterCursor is the condition for rows past the cursor in a listing
+// ordered by column and then id, with its arguments.
+func afterCursor(column string, desc bool, cursor *repository.Cursor) (string, []any) {
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	condition := ` AND (` + column + ` ` + op + ` ? OR (` + column + ` = ? AND id ` + op + ` ?))`
+	return condition, []any{cursor.Time, cursor.Time, cursor.ID}
+}
+
+// translateError maps sql.ErrNoRows to repository.ErrNotFound.
+func translateError(err error) error {
+	if err == sql.ErrNoRows {
+		return repository.ErrNotFound
+	}
+	return err
+}
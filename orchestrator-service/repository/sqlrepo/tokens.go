@@ -0,0 +1,121 @@
+package sqlrepo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"orchestrator-service/models"
+	"orchestrator-service/repository"
+)
+
+type refreshTokenRepository struct {
+	*conn
+}
+
+const refreshTokenColumns = `id, user_id, family_id, device_id, user_agent, created_at, expires_at, revoked_at, replaced_by`
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	_, err := r.exec(ctx, `INSERT INTO refresh_tokens (`+refreshTokenColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		token.ID, token.UserID, token.FamilyID, token.DeviceID, token.UserAgent,
+		token.CreatedAt, token.ExpiresAt, nullTime(token.RevokedAt), token.ReplacedBy)
+	return err
+}
+
+func (r *refreshTokenRepository) Get(ctx context.Context, id string) (*models.RefreshToken, error) {
+	var (
+		token     models.RefreshToken
+		revokedAt sql.NullTime
+	)
+
+	err := r.queryRow(ctx, `SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE id = ?`, id).
+		Scan(&token.ID, &token.UserID, &token.FamilyID, &token.DeviceID, &token.UserAgent,
+			&token.CreatedAt, &token.ExpiresAt, &revokedAt, &token.ReplacedBy)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	token.RevokedAt = revokedAt.Time
+	return &token, nil
+}
+
+func (r *refreshTokenRepository) Revoke(ctx context.Context, id string, at time.Time, replacedBy string) error {
+	_, err := r.exec(ctx, `UPDATE refresh_tokens SET revoked_at = ?, replaced_by = ? WHERE id = ?`, at, replacedBy, id)
+	return err
+}
+
+func (r *refreshTokenRepository) Rotate(ctx context.Context, id string, at time.Time, replacedBy string) error {
+	return r.update(ctx, `UPDATE refresh_tokens SET revoked_at = ?, replaced_by = ? WHERE id = ? AND revoked_at IS NULL`,
+		at, replacedBy, id)
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	_, err := r.exec(ctx, `UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ?`, at, familyID)
+	return err
+}
+
+func (r *refreshTokenRepository) RevokeAllForUser(ctx context.Context, userID string, at time.Time) error {
+	_, err := r.exec(ctx, `UPDATE refresh_tokens SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`, at, userID)
+	return err
+}
+
+type revokedTokenRepository struct {
+	*conn
+}
+
+func (r *revokedTokenRepository) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	// Drop entries whose tokens have expired on their own
+	if _, err := r.exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at < ?`, time.Now()); err != nil {
+		return err
+	}
+
+	_, err := r.exec(ctx, `INSERT INTO revoked_tokens (jti, expires_at) VALUES (?, ?) ON CONFLICT (jti) DO NOTHING`, jti, expiresAt)
+	return err
+}
+
+func (r *revokedTokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	var count int
+	if err := r.queryRow(ctx, `SELECT COUNT(*) FROM revoked_tokens WHERE jti = ?`, jti).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+type actionTokenRepository struct {
+	*conn
+}
+
+func (r *actionTokenRepository) Create(ctx context.Context, token *models.ActionToken) error {
+	_, err := r.exec(ctx, `INSERT INTO action_tokens (id, user_id, purpose, email, created_at, expires_at, used_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		token.ID, token.UserID, token.Purpose, token.Email, token.CreatedAt, token.ExpiresAt, nullTime(token.UsedAt))
+	return err
+}
+
+func (r *actionTokenRepository) Consume(ctx context.Context, id string, at time.Time) (*models.ActionToken, error) {
+	// The conditional update is what makes the token single use
+	res, err := r.exec(ctx, `UPDATE action_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL`, at, id)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, repository.ErrNotFound
+	}
+
+	var token models.ActionToken
+	err = r.queryRow(ctx, `SELECT id, user_id, purpose, email, created_at, expires_at FROM action_tokens WHERE id = ?`, id).
+		Scan(&token.ID, &token.UserID, &token.Purpose, &token.Email, &token.CreatedAt, &token.ExpiresAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	token.UsedAt = at
+	return &token, nil
+}
+
+// nullTime stores the zero time as NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t.UTC(), Valid: !t.IsZero()}
+}
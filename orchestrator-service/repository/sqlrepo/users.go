@@ -0,0 +1,115 @@
+package sqlrepo
+
+import (
+	"context"
+	"encoding/json"
+
+	"orchestrator-service/models"
+)
+
+type userRepository struct {
+	*conn
+}
+
+const userColumns = `id, email, email_verified, password, full_name, date_of_birth, gender, height, weight,
+	blood_type, allergies, medications, conditions, profile_image, settings,
+	provider, google_id, created_at, updated_at, totp_secret, totp_last_step, recovery_codes, timezone`
+
+func (r *userRepository) Get(ctx context.Context, id string) (*models.User, error) {
+	row := r.queryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id = ?`, id)
+	return scanUser(row)
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	row := r.queryRow(ctx, `SELECT `+userColumns+` FROM users WHERE email = ?`, email)
+	return scanUser(row)
+}
+
+func (r *userRepository) Save(ctx context.Context, user *models.User) error {
+	settings, err := json.Marshal(user.Settings)
+	if err != nil {
+		return err
+	}
+	recoveryCodes, err := json.Marshal(user.RecoveryCodes)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.exec(ctx, `INSERT INTO users (`+userColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			email = excluded.email,
+			email_verified = excluded.email_verified,
+			password = excluded.password,
+			full_name = excluded.full_name,
+			date_of_birth = excluded.date_of_birth,
+			gender = excluded.gender,
+			height = excluded.height,
+			weight = excluded.weight,
+			blood_type = excluded.blood_type,
+			allergies = excluded.allergies,
+			medications = excluded.medications,
+			conditions = excluded.conditions,
+			profile_image = excluded.profile_image,
+			settings = excluded.settings,
+			provider = excluded.provider,
+			google_id = excluded.google_id,
+			created_at = excluded.created_at,
+			updated_at = excluded.updated_at,
+			totp_secret = excluded.totp_secret,
+			totp_last_step = excluded.totp_last_step,
+			recovery_codes = excluded.recovery_codes,
+			timezone = excluded.timezone`,
+		user.ID, user.Email, user.EmailVerified, user.Password, user.FullName, user.DateOfBirth, user.Gender,
+		user.Height, user.Weight, user.BloodType, user.Allergies, user.Medications,
+		user.Conditions, user.ProfileImage, string(settings),
+		user.Provider, user.GoogleID, user.CreatedAt, user.UpdatedAt,
+		user.TOTPSecret, user.TOTPLastStep, string(recoveryCodes), user.Timezone)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row rowScanner) (*models.User, error) {
+	var (
+		user                    models.User
+		settings, recoveryCodes string
+	)
+
+	err := row.Scan(&user.ID, &user.Email, &user.EmailVerified, &user.Password, &user.FullName, &user.DateOfBirth,
+		&user.Gender, &user.Height, &user.Weight, &user.BloodType, &user.Allergies,
+		&user.Medications, &user.Conditions, &user.ProfileImage, &settings,
+		&user.Provider, &user.GoogleID, &user.CreatedAt, &user.UpdatedAt,
+		&user.TOTPSecret, &user.TOTPLastStep, &recoveryCodes, &user.Timezone)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	if err := json.Unmarshal([]byte(settings), &user.Settings); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(recoveryCodes), &user.RecoveryCodes); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) List(ctx context.Context) ([]models.User, error) {
+	rows, err := r.query(ctx, `SELECT `+userColumns+` FROM users ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+	return users, rows.Err()
+}
@@ -0,0 +1,184 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"unicode/utf8"
+
+	"orchestrator-service/models"
+)
+
+// BM25 parameters
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// snippetLength is roughly how many characters of context a result shows.
+const snippetLength = 160
+
+// Fragment is a piece of a snippet; Match marks the highlighted words.
+type Fragment struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match,omitempty"`
+}
+
+type Result struct {
+	Message   models.ChatMessage `json:"message"`
+	Score     float64            `json:"score"`
+	Fragments []Fragment         `json:"fragments"`
+}
+
+// Rank scores messages against the query terms with BM25, weighted by how
+// many of the terms each message contains, and returns those that match,
+// best first. Ties go to the newer message.
+//
+// Document frequencies come from the messages given, which are expected
+// to be every message containing at least one of the terms.
+func Rank(terms []string, messages []models.ChatMessage) []Result {
+	if len(terms) == 0 || len(messages) == 0 {
+		return nil
+	}
+
+	type doc struct {
+		tokens []Token
+		freq   map[string]int
+	}
+
+	docs := make([]doc, len(messages))
+	df := make(map[string]int)
+	totalLength := 0
+	for i, message := range messages {
+		tokens := Tokenize(message.Text)
+		freq := make(map[string]int)
+		for _, token := range tokens {
+			freq[token.Term]++
+		}
+		for _, term := range terms {
+			if freq[term] > 0 {
+				df[term]++
+			}
+		}
+		docs[i] = doc{tokens: tokens, freq: freq}
+		totalLength += len(tokens)
+	}
+
+	n := float64(len(messages))
+	avgLength := float64(totalLength) / n
+
+	var results []Result
+	for i, d := range docs {
+		var (
+			score   float64
+			matched int
+		)
+		for _, term := range terms {
+			tf := float64(d.freq[term])
+			if tf == 0 {
+				continue
+			}
+			matched++
+
+			idf := math.Log(1 + (n-float64(df[term])+0.5)/(float64(df[term])+0.5))
+			norm := 1 - b + b*float64(len(d.tokens))/avgLength
+			score += idf * tf * (k1 + 1) / (tf + k1*norm)
+		}
+		if matched == 0 {
+			continue
+		}
+
+		coverage := float64(matched) / float64(len(terms))
+		results = append(results, Result{
+			Message:   messages[i],
+			Score:     math.Round(score*(0.5+0.5*coverage)*1000) / 1000,
+			Fragments: highlight(messages[i].Text, d.tokens, terms),
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Message.Timestamp.After(results[j].Message.Timestamp)
+	})
+	return results
+}
+
+// highlight cuts a snippet around the first match and splits it into
+// fragments, marking every matched word.
+func highlight(text string, tokens []Token, terms []string) []Fragment {
+	wanted := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		wanted[term] = true
+	}
+
+	var matches []Token
+	for _, token := range tokens {
+		if wanted[token.Term] {
+			matches = append(matches, token)
+		}
+	}
+	if len(matches) == 0 {
+		return []Fragment{{Text: text}}
+	}
+
+	start, end := snippetBounds(text, matches[0])
+
+	var fragments []Fragment
+	if start > 0 {
+		fragments = append(fragments, Fragment{Text: "…"})
+	}
+	pos := start
+	for _, m := range matches {
+		if m.Start < start || m.End > end {
+			continue
+		}
+		if m.Start > pos {
+			fragments = append(fragments, Fragment{Text: text[pos:m.Start]})
+		}
+		fragments = append(fragments, Fragment{Text: text[m.Start:m.End], Match: true})
+		pos = m.End
+	}
+	if pos < end {
+		fragments = append(fragments, Fragment{Text: text[pos:end]})
+	}
+	if end < len(text) {
+		fragments = append(fragments, Fragment{Text: "…"})
+	}
+
+	// Join neighbouring ellipses into the text around them
+	merged := fragments[:0]
+	for _, f := range fragments {
+		if n := len(merged); n > 0 && !f.Match && !merged[n-1].Match {
+			merged[n-1].Text += f.Text
+			continue
+		}
+		merged = append(merged, f)
+	}
+	return merged
+}
+
+// snippetBounds picks about snippetLength characters around the match,
+// starting a third of the way before it, on character boundaries.
+func snippetBounds(text string, match Token) (int, int) {
+	if utf8.RuneCountInString(text) <= snippetLength {
+		return 0, len(text)
+	}
+
+	start := match.Start
+	for before := 0; start > 0 && before < snippetLength/3; before++ {
+		_, size := utf8.DecodeLastRuneInString(text[:start])
+		start -= size
+	}
+
+	end := start
+	for count := 0; end < len(text) && count < snippetLength; count++ {
+		_, size := utf8.DecodeRuneInString(text[end:])
+		end += size
+	}
+	if end < match.End {
+		end = match.End
+	}
+
+	return start, end
+}
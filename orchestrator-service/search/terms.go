@@ -0,0 +1,113 @@
+// Package search implements the chat history search: turning text into
+// index terms, ranking matching messages and highlighting the matches.
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxQueryTerms caps how many terms of a query are used.
+const MaxQueryTerms = 10
+
+// Token is one indexable word of a text, located by byte offsets.
+type Token struct {
+	Term       string
+	Start, End int
+}
+
+var stopWords = map[string]bool{
+	"a": true, "about": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "can": true, "did": true, "do": true, "does": true,
+	"for": true, "from": true, "had": true, "has": true, "have": true, "how": true,
+	"i": true, "if": true, "in": true, "is": true, "it": true, "its": true, "me": true,
+	"my": true, "of": true, "on": true, "or": true, "should": true, "so": true,
+	"that": true, "the": true, "this": true, "to": true, "was": true, "what": true,
+	"when": true, "which": true, "who": true, "why": true, "will": true, "with": true,
+	"you": true, "your": true,
+}
+
+// Tokenize splits text into words, lowercased and stemmed, skipping stop
+// words and single characters.
+func Tokenize(text string) []Token {
+	var (
+		tokens []Token
+		start  = -1
+	)
+
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		word := strings.ToLower(text[start:end])
+		if utf8.RuneCountInString(word) > 1 && !stopWords[word] {
+			tokens = append(tokens, Token{Term: stem(word), Start: start, End: end})
+		}
+		start = -1
+	}
+
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+	}
+	flush(len(text))
+
+	return tokens
+}
+
+// Terms returns the distinct terms of text, in order of first appearance.
+// These are what the index stores for each message.
+func Terms(text string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, token := range Tokenize(text) {
+		if !seen[token.Term] {
+			seen[token.Term] = true
+			terms = append(terms, token.Term)
+		}
+	}
+	return terms
+}
+
+// QueryTerms is Terms limited to MaxQueryTerms.
+func QueryTerms(query string) []string {
+	terms := Terms(query)
+	if len(terms) > MaxQueryTerms {
+		terms = terms[:MaxQueryTerms]
+	}
+	return terms
+}
+
+// stem strips common English suffixes so that "vitamins" finds "vitamin"
+// and "walked" finds "walking". It is deliberately crude: the index and
+// the query both go through it, so it only has to be consistent.
+func stem(word string) string {
+	long := func(s string) bool { return utf8.RuneCountInString(s) >= 3 }
+
+	switch {
+	case strings.HasSuffix(word, "ing") && long(strings.TrimSuffix(word, "ing")):
+		word = strings.TrimSuffix(word, "ing")
+	case strings.HasSuffix(word, "ed") && long(strings.TrimSuffix(word, "ed")):
+		word = strings.TrimSuffix(word, "ed")
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && long(strings.TrimSuffix(word, "s")):
+		word = strings.TrimSuffix(word, "s")
+	}
+
+	// "calorie" and "calories", "berry" and "berries" meet at "calori" and
+	// "berri"
+	if utf8.RuneCountInString(word) > 3 {
+		switch {
+		case strings.HasSuffix(word, "e"):
+			word = strings.TrimSuffix(word, "e")
+		case strings.HasSuffix(word, "y"):
+			word = strings.TrimSuffix(word, "y") + "i"
+		}
+	}
+	return word
+}
@@ -0,0 +1,110 @@
+package triage
+
+import "regexp"
+
+// DefaultResponses are the replies sent for each category unless a rule
+// sets its own.
+var DefaultResponses = map[string]string{
+	Emergency: "Your message describes symptoms that may need emergency care. " +
+		"Please call your local emergency number (112 in Europe, 911 in the US) " +
+		"or go to the nearest emergency department now. Don't wait to see if it gets better, " +
+		"and don't drive yourself if you can avoid it.\n\n" +
+		"*I can't assess emergencies. This is not medical advice.*",
+	SelfHarm: "I'm really sorry you're going through this. You don't have to deal with it alone. " +
+		"Please reach out to someone right now: a crisis line such as 988 (US), 116 123 (Samaritans, UK and Ireland) " +
+		"or your local helpline, or someone you trust. " +
+		"If you are in immediate danger, call your local emergency number (112 in Europe, 911 in the US).",
+}
+
+// DefaultRules are used unless TRIAGE_RULES points at a rules file.
+// Self-harm comes first so that, for example, an overdose described as
+// intentional gets the crisis response.
+var DefaultRules = []Rule{
+	{
+		Name:     "self_harm",
+		Category: SelfHarm,
+		Patterns: []string{
+			`\b(kill|hurt|harm|cut)(ing)? myself\b`,
+			`\bsuicid(e|al)\b`,
+			`\bend (my life|it all)\b`,
+			`\bwant(ed)? to die\b`,
+			`\bdon'?t want to (live|be alive|wake up)\b`,
+			`\bself[- ]harm`,
+			`\bno reason to live\b`,
+		},
+	},
+	{
+		Name:     "cardiac",
+		Category: Emergency,
+		Patterns: []string{
+			`\b(crushing|severe|sudden|sharp) (chest|heart) pain\b`,
+			`\bchest (pain|pressure|tightness)\b.*\b(arm|jaw|sweat|breath|nause)`,
+			`\bcrushing\b.*\bchest\b`,
+			`\b(having|had) a heart attack\b`,
+		},
+	},
+	{
+		Name:     "stroke",
+		Category: Emergency,
+		Patterns: []string{
+			`\b(face|facial) (is )?droop`,
+			`\bslurred speech\b`,
+			`\b(having|had) a stroke\b`,
+			`\bsudden(ly)? (weakness|numbness|paralysis|confusion|loss of vision)\b`,
+			`\b(can'?t|cannot) (move|feel) (one|my (left|right)) (side|arm|leg)\b`,
+		},
+	},
+	{
+		Name:     "breathing",
+		Category: Emergency,
+		Patterns: []string{
+			`\b(can'?t|cannot|unable to|struggling to) breathe\b`,
+			`\bchoking\b`,
+			`\b(lips|face) (are |is |turning )?(blue|grey|gray)\b`,
+		},
+	},
+	{
+		Name:     "anaphylaxis",
+		Category: Emergency,
+		Patterns: []string{
+			`\banaphyla`,
+			`\b(throat|tongue|lips?) (is |are )?(swelling|swollen|closing)\b`,
+		},
+	},
+	{
+		Name:     "bleeding",
+		Category: Emergency,
+		Patterns: []string{
+			`\b(severe|heavy|uncontrolled|profuse) bleeding\b`,
+			`\bbleeding (heavily|a lot|won'?t stop|that won'?t stop)\b`,
+			`\b(vomiting|coughing( up)?) blood\b`,
+		},
+	},
+	{
+		Name:     "consciousness",
+		Category: Emergency,
+		Patterns: []string{
+			`\b(passed out|fainted|unconscious|unresponsive)\b`,
+			`\b(having|had) a seizure\b`,
+		},
+	},
+	{
+		Name:     "poisoning",
+		Category: Emergency,
+		Patterns: []string{
+			`\boverdos(e|ed|ing)\b`,
+			`\b(swallowed|drank|ingested) (bleach|poison|antifreeze|detergent)\b`,
+		},
+	},
+}
+
+// clauseBreaks end the clause a negation or time applies to.
+const clauseBreaks = ".,;:!?\n"
+
+// negationPattern matches a clause ending in a negation, optionally followed
+// by an adverb, as in "no", "never really" or "I don't have".
+var negationPattern = regexp.MustCompile(`(?i)\b(?:no|not|never|without|don'?t have|do not have|didn'?t have|haven'?t had|hasn'?t had)(?:\s+(?:\w+ly|ever|even))?\s+$`)
+
+// pastPattern matches times long enough ago that an emergency is no longer
+// going on.
+var pastPattern = regexp.MustCompile(`(?i)\b(?:last (?:year|month)|(?:years|months|a year|a month) ago|as a (?:child|kid|teenager)|when i was (?:young|little|a (?:child|kid|teenager)))\b`)
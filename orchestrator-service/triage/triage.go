@@ -0,0 +1,142 @@
+// Package triage screens chat messages for emergencies and self-harm
+// signals, which must be answered with urgent-care guidance rather than
+// general advice.
+package triage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	Emergency = "emergency"
+	SelfHarm  = "self_harm"
+)
+
+// Rule flags messages matching any of its patterns. Patterns are regular
+// expressions matched case-insensitively.
+type Rule struct {
+	Name     string   `json:"name"`
+	Category string   `json:"category"`
+	Patterns []string `json:"patterns"`
+	// Response overrides the category's default reply.
+	Response string `json:"response,omitempty"`
+}
+
+// Result describes the rule a message triggered.
+type Result struct {
+	Category string
+	Rule     string
+	Matched  string // The text that matched
+	Response string
+}
+
+type compiledRule struct {
+	Rule
+	patterns []*regexp.Regexp
+}
+
+// Classifier checks messages against rules in order; the first match
+// wins, so the most serious rules should come first.
+type Classifier struct {
+	rules []compiledRule
+}
+
+func New(rules []Rule) (*Classifier, error) {
+	c := &Classifier{}
+	for _, rule := range rules {
+		if rule.Name == "" || rule.Category == "" {
+			return nil, fmt.Errorf("triage rule %q: name and category are required", rule.Name)
+		}
+		if rule.Response == "" && DefaultResponses[rule.Category] == "" {
+			return nil, fmt.Errorf("triage rule %q: no response for category %q", rule.Name, rule.Category)
+		}
+
+		compiled := compiledRule{Rule: rule}
+		for _, pattern := range rule.Patterns {
+			re, err := regexp.Compile(`(?i)` + pattern)
+			if err != nil {
+				return nil, fmt.Errorf("triage rule %q: %w", rule.Name, err)
+			}
+			compiled.patterns = append(compiled.patterns, re)
+		}
+		c.rules = append(c.rules, compiled)
+	}
+	return c, nil
+}
+
+// Default classifies with DefaultRules.
+func Default() *Classifier {
+	c, err := New(DefaultRules)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// LoadFile reads a JSON array of rules, replacing the defaults.
+func LoadFile(path string) (*Classifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("triage rules %s: %w", path, err)
+	}
+	return New(rules)
+}
+
+// Classify returns the first rule the text triggers, or nil. Matches that
+// are negated ("I don't have chest pain") are passed over, as are
+// emergencies placed well in the past ("passed out once last year").
+func (c *Classifier) Classify(text string) *Result {
+	for _, rule := range c.rules {
+		for _, pattern := range rule.patterns {
+			var matched string
+			for _, m := range pattern.FindAllStringIndex(text, -1) {
+				if !dismissed(text, m[0], m[1], rule.Category) {
+					matched = text[m[0]:m[1]]
+					break
+				}
+			}
+			if matched == "" {
+				continue
+			}
+
+			response := rule.Response
+			if response == "" {
+				response = DefaultResponses[rule.Category]
+			}
+			return &Result{
+				Category: rule.Category,
+				Rule:     rule.Name,
+				Matched:  matched,
+				Response: response,
+			}
+		}
+	}
+	return nil
+}
+
+// dismissed reports whether the match at [start, end) is negated within its
+// clause, or, for emergencies, said to have happened long ago. Past
+// self-harm still gets the crisis reply.
+func dismissed(text string, start, end int, category string) bool {
+	before, after := text[:start], text[end:]
+	if i := strings.LastIndexAny(before, clauseBreaks); i >= 0 {
+		before = before[i+1:]
+	}
+	if i := strings.IndexAny(after, clauseBreaks); i >= 0 {
+		after = after[:i]
+	}
+
+	if negationPattern.MatchString(before) {
+		return true
+	}
+	return category != SelfHarm && pastPattern.MatchString(after)
+}
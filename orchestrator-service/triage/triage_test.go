@@ -0,0 +1,52 @@
+package triage
+
+import "testing"
+
+type classifyCase struct {
+	text string
+	want string // The rule triggered, or "" for none
+}
+
+func runClassifyCases(t *testing.T, c *Classifier, cases []classifyCase) {
+	t.Helper()
+	for _, tc := range cases {
+		var got string
+		if result := c.Classify(tc.text); result != nil {
+			got = result.Rule
+		}
+		if got != tc.want {
+			t.Errorf("Classify(%q) = %q, want %q", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	runClassifyCases(t, Default(), []classifyCase{
+		{"I have chest pain going down my left arm", "cardiac"},
+		{"I think I'm having a heart attack", "cardiac"},
+		{"my dad's face is drooping", "stroke"},
+		{"I can't breathe", "breathing"},
+		{"I passed out at work this morning", "consciousness"},
+		{"I want to die", "self_harm"},
+		{"my son swallowed bleach", "poisoning"},
+		{"I'm not sure what to do, I want to die", "self_harm"},
+		{"Bleeding won't stop after a cut", "bleeding"},
+
+		// Negated
+		{"I don't have chest pain or shortness of breath", ""},
+		{"no chest pain, no shortness of breath", ""},
+		{"I have never passed out", ""},
+		{"I'm not having a heart attack, just stressed", ""},
+		{"I'm not suicidal, just tired", ""},
+		{"never really fainted before", ""},
+		{"no chest pain, but I passed out an hour ago", "consciousness"},
+
+		// Long ago
+		{"I passed out once last year", ""},
+		{"I had a seizure as a child", ""},
+		{"I had a stroke two years ago", ""},
+		{"I tried to kill myself years ago", "self_harm"},
+
+		{"how many steps should I walk a day?", ""},
+	})
+}
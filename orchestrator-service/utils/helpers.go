@@ -17,3 +17,13 @@ func GenerateID() string {
 	}
 	return fmt.Sprintf("%x", b)
 }
+
+// GenerateSecureToken returns 32 random bytes, hex encoded. Unlike
+// GenerateID it never falls back to a predictable value.
+func GenerateSecureToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", b), nil
+}
@@ -1,12 +1,27 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	// AccessTokenTTL is kept short; clients renew through a refresh token.
+	AccessTokenTTL = 15 * time.Minute
+	// RefreshTokenTTL bounds how long a device stays signed in while idle.
+	RefreshTokenTTL = 30 * 24 * time.Hour
+	// ChallengeTokenTTL is how long a user has to enter their second factor.
+	ChallengeTokenTTL = 5 * time.Minute
+)
+
+// PurposeTwoFactor marks a token that only proves the password step of a
+// two-factor login. It must not be accepted as an access token.
+const PurposeTwoFactor = "2fa"
+
 var jwtSecret = []byte(getJWTSecret())
 
 func getJWTSecret() string {
@@ -18,20 +33,35 @@ func getJWTSecret() string {
 }
 
 type Claims struct {
-	UserID string `json:"userId"`
-	Email  string `json:"email"`
+	UserID  string `json:"userId"`
+	Email   string `json:"email"`
+	Purpose string `json:"purpose,omitempty"` // Empty for access tokens
 	jwt.RegisteredClaims
 }
 
+// GenerateToken issues an access token. Each token carries a unique jti so
+// it can be revoked before it expires.
 func GenerateToken(userID, email string) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+	return signToken(userID, email, "", AccessTokenTTL)
+}
+
+// GenerateChallengeToken issues the short-lived token handed out after a
+// correct password when the account has two-factor authentication on.
+func GenerateChallengeToken(userID, email string) (string, error) {
+	return signToken(userID, email, PurposeTwoFactor, ChallengeTokenTTL)
+}
+
+func signToken(userID, email, purpose string, ttl time.Duration) (string, error) {
+	now := time.Now()
 
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
+		UserID:  userID,
+		Email:   email,
+		Purpose: purpose,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        GenerateID(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
@@ -44,7 +74,7 @@ func VerifyToken(tokenString string) (*Claims, error) {
 
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 		return jwtSecret, nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil {
 		return nil, err
@@ -56,3 +86,19 @@ func VerifyToken(tokenString string) (*Claims, error) {
 
 	return claims, nil
 }
+
+// GenerateRefreshToken returns a new opaque refresh token together with the
+// hash under which it is stored.
+func GenerateRefreshToken() (token, hash string, err error) {
+	token, err = GenerateSecureToken()
+	if err != nil {
+		return "", "", err
+	}
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the SHA-256 hex digest of an opaque token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,7 +1,9 @@
+import json
 from fastapi import FastAPI
+from fastapi.responses import StreamingResponse
 from pydantic import BaseModel
 from typing import List, Optional
-from rag import send_query
+from rag import send_query, send_query_stream
 
 app = FastAPI(title="RAG API")
 
@@ -10,9 +12,26 @@ class ChatHistoryItem(BaseModel):
     text: str
     timestamp: Optional[str] = None  
 
+class RecentActivity(BaseModel):
+    avgDailySteps: Optional[int] = None
+    activeMinutes: Optional[int] = None
+    avgSleep: Optional[float] = None
+    avgHeartRate: Optional[int] = None
+
+class HealthContext(BaseModel):
+    age: Optional[int] = None
+    gender: Optional[str] = None
+    height: Optional[float] = None
+    weight: Optional[float] = None
+    allergies: Optional[str] = None
+    medications: Optional[str] = None
+    conditions: Optional[str] = None
+    recentActivity: Optional[RecentActivity] = None
+
 class QueryRequest(BaseModel):
     query: str
     history: List[ChatHistoryItem] = []  
+    context: Optional[HealthContext] = None
 
 class QueryResponse(BaseModel):
     answer: str
@@ -23,5 +42,19 @@ async def handle_test():
 
 @app.post("/query", response_model=QueryResponse)
 async def handle_query(request: QueryRequest):
-    answer = send_query(request.query, request.history)
+    answer = send_query(request.query, request.history, request.context)
     return QueryResponse(answer=answer)
+
+@app.post("/query/stream")
+def handle_query_stream(request: QueryRequest):
+    # One JSON object per line: {"token": "..."} while generating, then
+    # {"done": true} or {"error": "..."}
+    def events():
+        try:
+            for token in send_query_stream(request.query, request.history, request.context):
+                yield json.dumps({"token": token}) + "\n"
+            yield json.dumps({"done": True}) + "\n"
+        except Exception as e:
+            yield json.dumps({"error": str(e)}) + "\n"
+
+    return StreamingResponse(events(), media_type="application/x-ndjson")
@@ -82,7 +82,62 @@ def compute_dynamic_k(query, min_k=5, max_k=15):
 
     return food_k, activity_k
 
-def send_query(query, history=None):
+def format_user_context(context):
+    """Render the optional health context as one "label: value" per line"""
+    if context is None:
+        return "No personal information provided."
+
+    labels = {
+        "age": "Age",
+        "gender": "Gender",
+        "height": "Height (cm)",
+        "weight": "Weight (kg)",
+        "allergies": "Allergies",
+        "medications": "Medications",
+        "conditions": "Conditions",
+    }
+    lines = [
+        f"{label}: {getattr(context, field)}"
+        for field, label in labels.items()
+        if getattr(context, field)
+    ]
+
+    activity = context.recentActivity
+    if activity is not None:
+        activity_labels = {
+            "avgDailySteps": "Average daily steps (last 7 days)",
+            "activeMinutes": "Active minutes (last 7 days)",
+            "avgSleep": "Average sleep in hours (last 7 days)",
+            "avgHeartRate": "Average heart rate (last 7 days)",
+        }
+        lines += [
+            f"{label}: {getattr(activity, field)}"
+            for field, label in activity_labels.items()
+            if getattr(activity, field)
+        ]
+
+    return "\n".join(lines) or "No personal information provided."
+
+def context_fields(context):
+    """Names of the health context fields that are set, for logging without their values"""
+    if context is None:
+        return []
+
+    fields = [
+        field
+        for field in ("age", "gender", "height", "weight", "allergies", "medications", "conditions")
+        if getattr(context, field)
+    ]
+    activity = context.recentActivity
+    if activity is not None:
+        fields += [
+            f"recentActivity.{field}"
+            for field in ("avgDailySteps", "activeMinutes", "avgSleep", "avgHeartRate")
+            if getattr(activity, field)
+        ]
+    return fields
+
+def build_prompt(query, history=None, context=None):
     if history is None:
         history = []
 
@@ -92,7 +147,7 @@ def send_query(query, history=None):
 
     dataset_type = classify_query(query)
     if dataset_type == "no":
-        return "I can't answer that question."
+        return None
 
     food_k, activity_k = compute_dynamic_k(query)
 
@@ -108,6 +163,7 @@ def send_query(query, history=None):
             contexts.append(f"--- ACTIVITY DATA ---\n{activity_context}")
 
     combined_context = "\n\n".join(contexts)
+    user_context = format_user_context(context)
 
     prompt = f"""
         ### ROLE
@@ -123,6 +179,8 @@ def send_query(query, history=None):
         you MUST respond ONLY with the following message and nothing else: "I'm sorry, but based on the provided information, 
         I cannot answer that question. Please try rephrasing or asking about a different topic."
         3.  **Conciseness:** Keep the answer structured, concise, and easy for a layperson to understand.
+        4.  **User Profile:** Use the USER PROFILE only to tailor the advice, for example to avoid foods the user is allergic to.
+        Never repeat it back verbatim.
 
         ### REQUIRED OUTPUT FORMAT
         You MUST structure your response using the following markdown format exactly. Do not add any conversational text before or after this structure.
@@ -141,6 +199,9 @@ def send_query(query, history=None):
         Consult with a qualified healthcare professional or registered dietitian for personalized advice.*
 
         ---
+        ### USER PROFILE
+        {user_context}
+
         ### CHAT HISTORY CONTEXT
         {chat_context}
 
@@ -151,7 +212,25 @@ def send_query(query, history=None):
         {query}
     """
 
-    print('[LOG]\n' + prompt)
+    # Only field names: the prompt holds the user's health data
+    print(f"[LOG] {dataset_type} query, profile fields: {', '.join(context_fields(context)) or 'none'}")
+    return prompt
+
+def send_query(query, history=None, context=None):
+    prompt = build_prompt(query, history, context)
+    if prompt is None:
+        return "I can't answer that question."
 
     response = model.generate_content(prompt)
-    return response.text
\ No newline at end of file
+    return response.text
+
+def send_query_stream(query, history=None, context=None):
+    """Yield the answer in chunks as the model produces them"""
+    prompt = build_prompt(query, history, context)
+    if prompt is None:
+        yield "I can't answer that question."
+        return
+
+    for chunk in model.generate_content(prompt, stream=True):
+        if chunk.text:
+            yield chunk.text
\ No newline at end of file